@@ -5,6 +5,9 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
+	"go/token"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
@@ -12,14 +15,73 @@ import (
 	"strings"
 )
 
+// OutputFormat is the format of the file CreateAPIView writes
+type OutputFormat string
+
+const (
+	// OutputFormatTokens is the flat token list format
+	OutputFormatTokens OutputFormat = "tokens"
+	// OutputFormatTree is the hierarchical tree token format
+	OutputFormatTree OutputFormat = "tree"
+	// OutputFormatHTML is a self-contained HTML page for sharing reviews outside APIView
+	OutputFormatHTML OutputFormat = "html"
+)
+
+// Options configures review generation
+type Options struct {
+	// Format of the output file. The zero value is equivalent to OutputFormatTokens.
+	Format OutputFormat
+	// TypeCheck resolves identifiers in type expressions with go/types instead of by their spelling, making
+	// navigation links exact. It type checks dependencies from source, which must be available locally.
+	TypeCheck bool
+	// Promoted adds a section listing the fields and methods embedded types promote to each struct and interface
+	Promoted bool
+	// Order is the order in which declarations are listed. The zero value is equivalent to OrderingKind.
+	Order Ordering
+	// StructTags shows struct field tags, which for model types define the wire names of fields
+	StructTags bool
+	// GOOS and GOARCH restrict the platforms whose files are indexed. When they're empty, the review
+	// is the union of the APIs of all platforms, noting exports available only on some platforms.
+	GOOS, GOARCH string
+	// Tags lists build tags satisfied in addition to those of the platform and Go release
+	Tags []string
+	// Rules is the path of a file configuring the levels of rules' diagnostics, and disabling rules.
+	// When it's empty, all rules run at their default levels.
+	Rules string
+	// SARIF is the path of a file to which to write the review's diagnostics in SARIF format.
+	// When it's empty, no SARIF file is written.
+	SARIF string
+}
+
 // CreateAPIView generates the output file that the API view tool uses.
-func CreateAPIView(pkgDir, outputDir string) error {
-	review, err := createReview(pkgDir)
+func CreateAPIView(pkgDir, outputDir string, opts Options) error {
+	review, err := createReview(pkgDir, opts)
 	if err != nil {
 		panic(err)
 	}
+	if opts.SARIF != "" {
+		if err = writeSARIFFile(opts.SARIF, review); err != nil {
+			return err
+		}
+	}
+	var doc any
+	switch opts.Format {
+	case "", OutputFormatTokens:
+		doc = review
+	case OutputFormatTree:
+		doc = NewCodeFile(review)
+	case OutputFormatHTML:
+		file, err := os.Create(filepath.Join(outputDir, review.Name+".html"))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return writeHTML(file, review)
+	default:
+		return fmt.Errorf("unknown output format %q", opts.Format)
+	}
 	filename := filepath.Join(outputDir, review.Name+".json")
-	file, _ := json.MarshalIndent(review, "", " ")
+	file, _ := json.MarshalIndent(doc, "", " ")
 	err = os.WriteFile(filename, file, 0644)
 	if err != nil {
 		return err
@@ -27,14 +89,19 @@ func CreateAPIView(pkgDir, outputDir string) error {
 	return nil
 }
 
-func createReview(pkgDir string) (PackageReview, error) {
-	m, err := NewModule(pkgDir)
+func createReview(pkgDir string, opts Options) (PackageReview, error) {
+	if !slices.Contains(orderings, opts.Order) {
+		return PackageReview{}, fmt.Errorf("unknown ordering %q", opts.Order)
+	}
+	m, err := NewModule(pkgDir, opts)
 	if err != nil {
 		return PackageReview{}, err
 	}
-	tokenList := &[]Token{}
+	lines := []tokenLine{}
 	nav := []Navigation{}
 	diagnostics := []Diagnostic{}
+	positions := map[string]token.Position{}
+	platformNotes := map[string]string{}
 	packageNames := []string{}
 	for name, p := range m.packages {
 		// we use a prefixed path separator so that we can handle the "internal" module.
@@ -52,19 +119,16 @@ func createReview(pkgDir string) (PackageReview, error) {
 	for _, name := range packageNames {
 		p := m.packages[name]
 		n := p.relName
-		makeToken(nil, nil, "package", TokenTypeMemberName, tokenList)
-		makeToken(nil, nil, " ", TokenTypeWhitespace, tokenList)
-		makeToken(&n, nil, n, TokenTypeTypeName, tokenList)
-		makeToken(nil, nil, "", TokenTypeNewline, tokenList)
-		makeToken(nil, nil, "", TokenTypeNewline, tokenList)
-		// TODO: reordering these calls reorders APIView output and can omit content
-		p.c.parseInterface(tokenList)
-		p.c.parseStruct(tokenList)
-		p.c.parseSimpleType(tokenList)
-		p.c.parseVar(tokenList)
-		p.c.parseConst(tokenList)
-		p.c.parseFunc(tokenList)
-		navItems := p.c.generateNavChildItems()
+		header := &[]Token{}
+		makeToken(nil, nil, "package", TokenTypeMemberName, header)
+		makeToken(nil, nil, " ", TokenTypeWhitespace, header)
+		makeToken(&n, nil, n, TokenTypeTypeName, header)
+		lines = append(lines, tokenLine{tokens: *header}, blankLine)
+		maps.Copy(positions, p.c.positions())
+		maps.Copy(platformNotes, p.platformNotes)
+		l := newLayout(p.c)
+		l.render(opts.Order, &lines)
+		navItems := l.generateNavChildItems()
 		nav = append(nav, Navigation{
 			Text:         n,
 			NavigationId: n,
@@ -90,14 +154,17 @@ func createReview(pkgDir string) (PackageReview, error) {
 	for _, n := range nav {
 		recursiveSortNavigation(n)
 	}
+	lines = insertPlatformNotes(lines, platformNotes)
 
 	return PackageReview{
 		Diagnostics: diagnostics,
 		Language:    "Go",
 		Name:        m.Name,
 		Navigation:  nav,
-		Tokens:      *tokenList,
+		Tokens:      flatTokens(lines),
 		PackageName: m.PackageName,
+		lines:       lines,
+		positions:   positions,
 	}, nil
 }
 
@@ -5,8 +5,12 @@ package cmd
 
 import (
 	"encoding/json"
+	"go/ast"
+	"go/parser"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -18,7 +22,7 @@ func TestMain(m *testing.M) {
 }
 
 func TestFuncDecl(t *testing.T) {
-	p, err := createReview(filepath.Clean("testdata/test_func_decl"))
+	p, err := createReview(filepath.Clean("testdata/test_func_decl"), Options{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -37,7 +41,7 @@ func TestFuncDecl(t *testing.T) {
 }
 
 func TestInterface(t *testing.T) {
-	p, err := createReview(filepath.Clean("testdata/test_interface"))
+	p, err := createReview(filepath.Clean("testdata/test_interface"), Options{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -62,7 +66,7 @@ func TestMultiModule(t *testing.T) {
 		"testdata/test_multi_module/A/B",
 	} {
 		t.Run(path, func(t *testing.T) {
-			p, err := createReview(filepath.Clean(path))
+			p, err := createReview(filepath.Clean(path), Options{})
 			require.NoError(t, err)
 			require.Equal(t, 1, len(p.Navigation), "review should include only one package")
 			require.Equal(t, filepath.Base(path), p.Navigation[0].Text, "review includes the wrong module")
@@ -71,7 +75,7 @@ func TestMultiModule(t *testing.T) {
 }
 
 func TestStruct(t *testing.T) {
-	p, err := createReview(filepath.Clean("testdata/test_struct"))
+	p, err := createReview(filepath.Clean("testdata/test_struct"), Options{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -90,11 +94,12 @@ func TestStruct(t *testing.T) {
 }
 
 func TestConst(t *testing.T) {
-	p, err := createReview(filepath.Clean("testdata/test_const"))
+	p, err := createReview(filepath.Clean("testdata/test_const"), Options{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	if len(p.Tokens) != 76 {
+	// 76 tokens, plus a space and a comment showing the source expression of Agent's evaluated value
+	if len(p.Tokens) != 78 {
 		t.Fatal("unexpected token length, signals a change in the output")
 	}
 	if p.Name != "test_const" {
@@ -108,8 +113,44 @@ func TestConst(t *testing.T) {
 	}
 }
 
+func TestConstEval(t *testing.T) {
+	m, err := NewModule(filepath.Clean("testdata/test_const_eval"), Options{})
+	require.NoError(t, err)
+	lines := apiLines(m)
+	for _, line := range []string{
+		"pkg test_const_eval, const Agent = \"foo/0.1.0\"",
+		"pkg test_const_eval, const B Size = 1",
+		"pkg test_const_eval, const KB Size = 1024",
+		"pkg test_const_eval, const MB Size = 1048576",
+		"pkg test_const_eval, const First = 0",
+		"pkg test_const_eval, const Second = 10",
+		"pkg test_const_eval, const Third = 1",
+		"pkg test_const_eval, const Fourth = 11",
+		"pkg test_const_eval, const Mask = 127",
+		"pkg test_const_eval, const Enabled = true",
+		"pkg test_const_eval, const Quarter = 0.25",
+		"pkg test_const_eval, const Letter = \"A\"",
+		"pkg test_const_eval, const Formatted = \"raw\"",
+		// values of other packages' consts aren't known without type checking
+		"pkg test_const_eval, const Remote = math.MaxInt8",
+		// the complement of a named unsigned type depends on its underlying type's size
+		"pkg test_const_eval, const AllFlags = 255",
+		// calls of builtins and of unsafe's funcs aren't conversions, so their values aren't known without type checking
+		"pkg test_const_eval, const RealPart = real(Complex)",
+		"pkg test_const_eval, const ImagPart = imag(Complex)",
+		"pkg test_const_eval, const Width = unsafe.Sizeof(Hex)",
+	} {
+		require.Contains(t, lines, line)
+	}
+	m, err = NewModule(filepath.Clean("testdata/test_const_eval"), Options{TypeCheck: true})
+	require.NoError(t, err)
+	lines = apiLines(m)
+	require.Contains(t, lines, "pkg test_const_eval, const Remote = 127")
+	require.Contains(t, lines, "pkg test_const_eval, const RealPart = 1")
+}
+
 func TestSubpackage(t *testing.T) {
-	review, err := createReview(filepath.Clean("testdata/test_subpackage"))
+	review, err := createReview(filepath.Clean("testdata/test_subpackage"), Options{})
 	require.NoError(t, err)
 	require.Equal(t, "Go", review.Language)
 	require.Equal(t, "test_subpackage", review.Name)
@@ -137,7 +178,7 @@ func TestSubpackage(t *testing.T) {
 }
 
 func TestDiagnostics(t *testing.T) {
-	review, err := createReview(filepath.Clean("testdata/test_diagnostics"))
+	review, err := createReview(filepath.Clean("testdata/test_diagnostics"), Options{})
 	require.NoError(t, err)
 	require.Equal(t, "Go", review.Language)
 	require.Equal(t, "test_diagnostics", review.Name)
@@ -191,7 +232,7 @@ func TestAliasDefinitions(t *testing.T) {
 		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
-			review, err := createReview(filepath.Clean(test.path))
+			review, err := createReview(filepath.Clean(test.path), Options{})
 			require.NoError(t, err)
 			require.Equal(t, "Go", review.Language)
 			require.Equal(t, 1, len(review.Diagnostics))
@@ -226,7 +267,7 @@ func TestRecursiveAliasDefinitions(t *testing.T) {
 		},
 	} {
 		t.Run(test.name, func(t *testing.T) {
-			review, err := createReview(filepath.Clean(test.path))
+			review, err := createReview(filepath.Clean(test.path), Options{})
 			require.NoError(t, err)
 			require.Equal(t, "Go", review.Language)
 			require.Equal(t, 2, len(review.Diagnostics))
@@ -245,7 +286,7 @@ func TestRecursiveAliasDefinitions(t *testing.T) {
 }
 
 func TestAliasDiagnostics(t *testing.T) {
-	review, err := createReview(filepath.Clean("testdata/test_alias_diagnostics"))
+	review, err := createReview(filepath.Clean("testdata/test_alias_diagnostics"), Options{})
 	require.NoError(t, err)
 	require.Equal(t, "Go", review.Language)
 	require.Equal(t, "test_alias_diagnostics", review.Name)
@@ -276,7 +317,7 @@ func TestAliasDiagnostics(t *testing.T) {
 }
 
 func TestVars(t *testing.T) {
-	review, err := createReview(filepath.Clean("testdata/test_vars"))
+	review, err := createReview(filepath.Clean("testdata/test_vars"), Options{})
 	require.NoError(t, err)
 	require.NotZero(t, review)
 	countSomeChoice := 0
@@ -302,9 +343,9 @@ func Test_getPackageNameFromModPath(t *testing.T) {
 
 func TestDeterministicOutput(t *testing.T) {
 	for i := 0; i < 100; i++ {
-		review1, err := createReview(filepath.Clean("testdata/test_multi_recursive_alias"))
+		review1, err := createReview(filepath.Clean("testdata/test_multi_recursive_alias"), Options{})
 		require.NoError(t, err)
-		review2, err := createReview(filepath.Clean("testdata/test_multi_recursive_alias"))
+		review2, err := createReview(filepath.Clean("testdata/test_multi_recursive_alias"), Options{})
 		require.NoError(t, err)
 
 		output1, err := json.MarshalIndent(review1, "", " ")
@@ -315,3 +356,575 @@ func TestDeterministicOutput(t *testing.T) {
 		require.EqualValues(t, string(output1), string(output2))
 	}
 }
+
+func TestReviewLines(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_struct"), Options{})
+	require.NoError(t, err)
+	cf := NewCodeFile(review)
+	require.Equal(t, "Go", cf.Language)
+	var structLine *ReviewLine
+	for i, line := range cf.ReviewLines {
+		if line.LineID == "test_struct.SomeStruct" {
+			structLine = &cf.ReviewLines[i]
+			require.True(t, cf.ReviewLines[i+1].IsContextEndLine, "struct should be followed by its closing line")
+			break
+		}
+	}
+	require.NotNil(t, structLine)
+	require.Len(t, structLine.Children, 2)
+	require.Equal(t, "Foo-test_struct.SomeStruct", structLine.Children[0].LineID)
+	require.Equal(t, "Resp-test_struct.SomeStruct", structLine.Children[1].LineID)
+	for _, tok := range structLine.Tokens {
+		if tok.Value == "SomeStruct" {
+			require.Equal(t, "SomeStruct", tok.NavigationDisplayName)
+			require.Contains(t, tok.RenderClasses, "class")
+		}
+	}
+	for _, line := range cf.ReviewLines {
+		for _, tok := range line.Tokens {
+			require.NotEqual(t, " ", tok.Value, "whitespace should be expressed as suffix spaces")
+		}
+	}
+
+	review, err = createReview(filepath.Clean("testdata/test_const"), Options{})
+	require.NoError(t, err)
+	cf = NewCodeFile(review)
+	blocks := 0
+	for _, line := range cf.ReviewLines {
+		if len(line.Tokens) > 0 && line.Tokens[0].Value == "const" {
+			require.NotEmpty(t, line.Children)
+			blocks++
+		}
+	}
+	require.Equal(t, 3, blocks)
+}
+
+func TestReviewLinesEmbeddedInterfaces(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_embedded_interface"), Options{})
+	require.NoError(t, err)
+	cf := NewCodeFile(review)
+	for i, line := range cf.ReviewLines {
+		if line.LineID != "test_embedded_interface.ReadCloser" {
+			continue
+		}
+		require.Len(t, line.Children, 2)
+		for _, child := range line.Children {
+			require.Len(t, child.Tokens, 1, "the closing brace should be on its own line")
+		}
+		closing := cf.ReviewLines[i+1]
+		require.True(t, closing.IsContextEndLine)
+		require.Equal(t, "}", closing.Tokens[0].Value)
+		return
+	}
+	t.Fatal("missing ReadCloser")
+}
+
+func TestDocComments(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_doc_comments"), Options{})
+	require.NoError(t, err)
+	comments := []string{}
+	for i, token := range review.Tokens {
+		if token.Kind == TokenTypeComment {
+			comments = append(comments, token.Value)
+			// every comment is on its own line
+			require.Equal(t, TokenTypeNewline, review.Tokens[i+1].Kind)
+		}
+	}
+	require.Equal(t, []string{
+		"// Shape is an interface.",
+		"// Area is an interface method.",
+		"// Widget is a struct.",
+		"//",
+		"// It has two paragraphs.",
+		"// Name is a field.",
+		"// NewWidget creates a Widget.",
+		"// Resize is a method.",
+		"// DefaultWidget is a var.",
+		"// Color is a simple type.",
+		"// ColorRed is red.",
+		"// Version is an ungrouped const.",
+	}, comments)
+
+	cf := NewCodeFile(review)
+	for _, line := range cf.ReviewLines {
+		if len(line.Tokens) == 1 && line.Tokens[0].Value == "// NewWidget creates a Widget." {
+			require.Equal(t, "test_doc_comments-NewWidget", line.RelatedToLine)
+			return
+		}
+	}
+	t.Fatal("missing doc comment for NewWidget")
+}
+
+func TestDeprecated(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_deprecated"), Options{})
+	require.NoError(t, err)
+	expected := map[string]Diagnostic{
+		"Size-test_deprecated.Widget":     {Level: DiagnosticLevelWarning, Text: deprecatedNoReplacement + "no longer used."},
+		"test_deprecated-(w *Widget) Run": {Level: DiagnosticLevelInfo, Text: deprecatedAPI + "Run has been replaced by [Gadget.Run]."},
+		"test_deprecated-OldFunc":         {Level: DiagnosticLevelWarning, Text: deprecatedNoReplacement + "this paragraph continues."},
+		"test_deprecated-Shape-Area":      {Level: DiagnosticLevelWarning, Text: deprecatedNoReplacement + "it's inaccurate."},
+		"test_deprecated.OldColor":        {Level: DiagnosticLevelInfo, Text: deprecatedAPI + "prefer NewColor."},
+		"test_deprecated.Widget":          {Level: DiagnosticLevelInfo, Text: deprecatedAPI + "use [Gadget] instead."},
+	}
+	require.Len(t, review.Diagnostics, len(expected))
+	for _, d := range review.Diagnostics {
+		e, ok := expected[d.TargetID]
+		require.True(t, ok, "unexpected target "+d.TargetID)
+		require.Equal(t, e.Level, d.Level)
+		require.Equal(t, e.Text, d.Text)
+	}
+
+	deprecatedNav := []string{}
+	for _, item := range review.Navigation[0].ChildItems {
+		if (*item.Tags)["Deprecated"] == "true" {
+			deprecatedNav = append(deprecatedNav, item.Text)
+		}
+	}
+	require.ElementsMatch(t, []string{"OldColor", "OldFunc", "Widget"}, deprecatedNav)
+
+	// every deprecated range is closed and names a deprecated API
+	open := 0
+	deprecated := map[string]bool{}
+	for _, token := range review.Tokens {
+		switch token.Kind {
+		case TokenTypeDeprecatedRangeStart:
+			open++
+		case TokenTypeDeprecatedRangeEnd:
+			open--
+		default:
+			if open > 0 && token.DefinitionID != nil {
+				deprecated[*token.DefinitionID] = true
+			}
+		}
+	}
+	require.Zero(t, open)
+	for id := range expected {
+		require.True(t, deprecated[id], id+" should be in a deprecated range")
+	}
+	// fields of a deprecated struct are within its range
+	require.True(t, deprecated["Name-test_deprecated.Widget"])
+	require.Len(t, deprecated, len(expected)+1)
+}
+
+func TestTypeCheck(t *testing.T) {
+	// links maps each referenced identifier to the navigation targets of its occurrences
+	links := func(opts Options) map[string][]string {
+		review, err := createReview(filepath.Clean("testdata/test_type_check"), opts)
+		require.NoError(t, err)
+		links := map[string][]string{}
+		for _, token := range review.Tokens {
+			if token.Kind == TokenTypeTypeName || token.Kind == TokenTypeMemberName {
+				nav := ""
+				if token.NavigateToID != nil {
+					nav = *token.NavigateToID
+				}
+				if !slices.Contains(links[token.Value], nav) {
+					links[token.Value] = append(links[token.Value], nav)
+				}
+			}
+		}
+		return links
+	}
+
+	// spelling based translation assumes dot imported types are defined in the importing package
+	untyped := links(Options{})
+	require.Equal(t, []string{""}, untyped["T"])
+	require.Equal(t, []string{""}, untyped["req"])
+	require.Contains(t, untyped["Widget"], "test_type_check.Widget")
+
+	typed := links(Options{TypeCheck: true})
+	require.Equal(t, []string{""}, typed["T"])
+	require.Equal(t, []string{""}, typed["req"])
+	require.Contains(t, typed["Request"], "test_type_check.Request")
+	require.ElementsMatch(t, []string{"", "test_type_check/models.Widget"}, typed["Widget"])
+	require.Equal(t, []string{""}, typed["time.Duration"])
+}
+
+func TestPromoted(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_promoted"), Options{})
+	require.NoError(t, err)
+	for _, token := range review.Tokens {
+		require.NotEqual(t, TokenTypeLineIDMarker, token.Kind, "promoted members should be listed only when requested")
+	}
+
+	review, err = createReview(filepath.Clean("testdata/test_promoted"), Options{Promoted: true})
+	require.NoError(t, err)
+	promoted := map[string][]string{}
+	for _, line := range newReviewLines(review.lines, review.Navigation) {
+		id, found := strings.CutSuffix(line.LineID, "-promoted")
+		if !found {
+			continue
+		}
+		for _, child := range line.Children {
+			text := ""
+			for _, token := range child.Tokens {
+				text += token.Value
+				if token.HasSuffixSpace {
+					text += " "
+				}
+			}
+			promoted[id] = append(promoted[id], text)
+		}
+	}
+	require.Equal(t, map[string][]string{
+		// ambiguous selectors aren't promoted
+		"test_promoted.Both": {"OnlyA int // from A", "OnlyB int // from B"},
+		// members of types hoisted from aliases
+		"test_promoted.Client": {"Endpoint string // from Base", "URL() string // from Base"},
+		// members of types embedded at any depth
+		"test_promoted.FooResponse": {
+			"ETag string // from Model.Inner",
+			"ID *string // from Model",
+			"Inner Inner // from Model",
+			"Reset() // from Model.Inner",
+			"Validate() error // from Model",
+		},
+		// members of a type embedded through several struct paths of the same depth are ambiguous
+		"test_promoted.Diamond": {"OnlyLeft int // from Left", "OnlyRight int // from Right"},
+		"test_promoted.Left":    {"Common string // from Shared"},
+		"test_promoted.Right":   {"Common string // from Shared"},
+		// interfaces embedding the same interface share its methods
+		"test_promoted.Flusher":     {"Read() ([]byte, error) // from Reader"},
+		"test_promoted.FlushSyncer": {"Flush() // from Flusher", "Read() ([]byte, error) // from Flusher.Reader", "Sync() // from Syncer"},
+		"test_promoted.ReadCloser":  {"Read() ([]byte, error) // from Reader"},
+		"test_promoted.Syncer":      {"Read() ([]byte, error) // from Reader"},
+		// declared members hide promoted members of the same name
+		"test_promoted.Shadow": {
+			"ETag string // from Model.Inner",
+			"Inner Inner // from Model",
+			"Reset() // from Model.Inner",
+		},
+		"test_promoted/models.Model": {"ETag string // from Inner", "Reset() // from Inner"},
+	}, promoted)
+}
+
+// reviewLineText returns a review line's text without comments, collapsing whitespace
+func reviewLineText(rl ReviewLine) string {
+	sb := strings.Builder{}
+	for _, t := range rl.Tokens {
+		if t.Kind == ReviewTokenKindComment {
+			continue
+		}
+		sb.WriteString(t.Value)
+		if t.HasSuffixSpace {
+			sb.WriteString(" ")
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+func TestGenerics(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_generics"), Options{})
+	require.NoError(t, err)
+	text := map[string]string{}
+	for _, line := range newReviewLines(review.lines, review.Navigation) {
+		if line.LineID == "" {
+			continue
+		}
+		text[line.LineID] = reviewLineText(line)
+		for _, child := range line.Children {
+			text[line.LineID] += "; " + reviewLineText(child)
+		}
+	}
+	require.Equal(t, "type Container[T any] interface {; Get() T; Put(T)", text["test_generics.Container"])
+	require.Equal(t, "type Number interface {; ~int | ~int64 | float64", text["test_generics.Number"])
+	require.Equal(t, "type Ordered interface {; ~int | ~string; String() string", text["test_generics.Ordered"])
+	require.Equal(t, "type List[T any] []T", text["test_generics.List"])
+	require.Equal(t, "type Map[K comparable, V Number] map[K]V", text["test_generics.Map"])
+	require.Equal(t, "type Pair[K comparable, V any] struct {; Key K; Value V; W *Widget", text["test_generics.Pair"])
+	require.Equal(t, "func Sum[S ~[]E, E Number](s S) E", text["test_generics-Sum"])
+
+	// type parameters aren't links, unlike the types constraining them
+	for _, token := range review.Tokens {
+		if token.NavigateToID == nil {
+			continue
+		}
+		switch token.Value {
+		case "Number", "Widget", "Map":
+			require.Equal(t, "test_generics."+token.Value, *token.NavigateToID)
+		default:
+			t.Fatalf("unexpected link from %q to %q", token.Value, *token.NavigateToID)
+		}
+	}
+}
+
+func TestVarTypes(t *testing.T) {
+	lines := moduleAPI(t, "testdata/test_var_types", Options{})
+	for _, line := range []string{
+		"pkg test_var_types, var DefaultWidget *Widget = NewWidget()",
+		"pkg test_var_types, var DefaultClient *clients.Client = clients.New()",
+		"pkg test_var_types, var IntBox *Box[int] = NewBox[int](42)",
+		"pkg test_var_types, var StringBox *Box[string] = NewBox(\"hello\")",
+		"pkg test_var_types, var First Widget = Split()",
+		"pkg test_var_types, var Second error = Split()",
+		// errors.New isn't defined in the module
+		"pkg test_var_types, var ErrFailed = errors.New(\"failed\")",
+	} {
+		require.Contains(t, lines, line)
+	}
+
+	// navigation maps the names of vars to the navigation target of the first link following them
+	review, err := createReview(filepath.Clean("testdata/test_var_types"), Options{})
+	require.NoError(t, err)
+	navigation := map[string]string{}
+	name := ""
+	for _, token := range review.Tokens {
+		switch {
+		case token.Kind == TokenTypeNewline:
+			name = ""
+		case token.DefinitionID != nil:
+			name = token.Value
+		case token.NavigateToID != nil && name != "":
+			if _, ok := navigation[name]; !ok {
+				navigation[name] = *token.NavigateToID
+			}
+		}
+	}
+	require.Equal(t, "test_var_types/clients.Client", navigation["DefaultClient"])
+	require.Equal(t, "test_var_types.Widget", navigation["First"])
+
+	// vars are placed after the declarations of their types
+	var names []string
+	for _, token := range review.Tokens {
+		if token.DefinitionID != nil {
+			names = append(names, *token.DefinitionID)
+		}
+	}
+	require.Less(t, slices.Index(names, "test_var_types.Box"), slices.Index(names, "test_var_types.IntBox"))
+	require.Less(t, slices.Index(names, "test_var_types.Widget"), slices.Index(names, "test_var_types.DefaultWidget"))
+}
+
+func TestPlatforms(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_platforms"), Options{})
+	require.NoError(t, err)
+	lines := moduleAPI(t, "testdata/test_platforms", Options{})
+	require.Contains(t, lines, "pkg test_platforms, func Dial() (*Conn, error)")
+	// "ignore" and "debug" tagged files are excluded
+	require.NotContains(t, lines, "pkg test_platforms, func Trace()")
+	notes := []string{}
+	for _, token := range review.Tokens {
+		if token.Kind == TokenTypeComment && strings.HasPrefix(token.Value, "// Platforms: ") {
+			notes = append(notes, token.Value)
+		}
+	}
+	// variants of Handle and DefaultPath differ, and aren't available on e.g. plan9. Dial is available on all platforms.
+	require.Equal(t, []string{"// Platforms: unix, windows (declarations differ)", "// Platforms: unix, windows (declarations differ)"}, notes)
+	diagnostics := map[string][]string{}
+	for _, d := range review.Diagnostics {
+		diagnostics[d.TargetID] = append(diagnostics[d.TargetID], d.Text)
+	}
+	for _, id := range []string{"test_platforms-(c *Conn) Handle", "test_platforms.DefaultPath"} {
+		require.ElementsMatch(t, []string{platformSpecific + "unix, windows", platformVariants + "unix, windows"}, diagnostics[id])
+	}
+	require.NotContains(t, diagnostics, "test_platforms-Dial")
+
+	review, err = createReview(filepath.Clean("testdata/test_platforms"), Options{GOOS: "windows", Tags: []string{"debug"}})
+	require.NoError(t, err)
+	lines = moduleAPI(t, "testdata/test_platforms", Options{GOOS: "windows", Tags: []string{"debug"}})
+	require.Contains(t, lines, "pkg test_platforms, method (*Conn) Handle() uintptr")
+	require.Contains(t, lines, "pkg test_platforms, const DefaultPath = \"\\\\\\\\.\\\\pipe\\\\conn\"")
+	require.Contains(t, lines, "pkg test_platforms, func Trace()")
+	require.Empty(t, review.Diagnostics)
+	for _, token := range review.Tokens {
+		require.False(t, strings.HasPrefix(token.Value, "// Platforms: "), "all exports are available on windows")
+	}
+}
+
+func TestTypeTokens(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_type_tokens"), Options{})
+	require.NoError(t, err)
+	lines := moduleAPI(t, "testdata/test_type_tokens", Options{})
+	for _, line := range []string{
+		"pkg test_type_tokens, type Stream struct, Events <-chan Event",
+		"pkg test_type_tokens, type Stream struct, Acks chan<- bool",
+		"pkg test_type_tokens, type Stream struct, Options struct{ Name string `json:\"name\"`; Retry int }",
+		"pkg test_type_tokens, type Stream struct, Logger interface{ Log(string) }",
+		"pkg test_type_tokens, type Stream struct, Items models.List[models.Item]",
+		"pkg test_type_tokens, type Handler func(Event, int) (bool, error)",
+		"pkg test_type_tokens, func Subscribe(func(Event) error, <-chan struct{}) map[string][]*models.Item",
+	} {
+		require.Contains(t, lines, line)
+	}
+
+	// kinds maps token values to their kinds, and links maps them to their navigation targets
+	kinds := map[string]TokenType{}
+	links := map[string]string{}
+	for _, token := range review.Tokens {
+		if token.Kind == TokenTypeWhitespace || token.Kind == TokenTypeNewline {
+			continue
+		}
+		kinds[token.Value] = token.Kind
+		if token.NavigateToID != nil {
+			links[token.Value] = *token.NavigateToID
+		}
+	}
+	require.Equal(t, TokenTypeKeyword, kinds["chan"])
+	require.Equal(t, TokenTypeKeyword, kinds["struct"])
+	require.Equal(t, TokenTypePunctuation, kinds["<-"])
+	require.Equal(t, TokenTypeMemberName, kinds["retries"])
+	require.Equal(t, TokenTypeMemberName, kinds["Log"])
+	require.Equal(t, TokenTypeStringLiteral, kinds["`json:\"name\"`"])
+	require.Equal(t, "test_type_tokens/models.List", links["models.List"])
+	require.Equal(t, "test_type_tokens/models.Item", links["models.Item"])
+	require.Equal(t, "test_type_tokens.Event", links["Event"])
+	require.NotContains(t, links, "retries")
+	require.NotContains(t, links, "msg")
+}
+
+func TestTypeExpr(t *testing.T) {
+	expr, err := parser.ParseExpr("map[string]*sub.Bar")
+	require.NoError(t, err)
+	typ, ok := exprType(expr, func(e ast.Expr) string {
+		if sel, ok := e.(*ast.SelectorExpr); ok && sel.Sel.Name == "Bar" {
+			return "mod/sub.Bar"
+		}
+		return ""
+	})
+	require.True(t, ok)
+	require.Equal(t, "map[string]*sub.Bar", typ.text)
+	require.Equal(t, "", typ.navigator())
+	links := map[string]string{}
+	for _, tok := range typ.tokens {
+		if tok.NavigateToID != nil {
+			links[tok.Value] = *tok.NavigateToID
+		}
+	}
+	require.Equal(t, map[string]string{"sub.Bar": "mod/sub.Bar"}, links)
+
+	// qualified identifiers are already qualified
+	require.Equal(t, "map[string]*sub.Bar", qualifyTypes(typ, "mod/sub", "s").text)
+	require.Empty(t, typ.unlink(map[string]bool{"mod/sub.Bar": true}).navigator())
+
+	expr, err = parser.ParseExpr("*Set[T]")
+	require.NoError(t, err)
+	set, ok := exprType(expr, func(e ast.Expr) string {
+		if id, ok := e.(*ast.Ident); ok && id.Name == "Set" {
+			return "pkg.Set"
+		}
+		return ""
+	})
+	require.True(t, ok)
+	require.Equal(t, "pkg.Set", set.navigator())
+	require.Equal(t, "*Set[string]", substituteTypeParam(set, "T", textType("string")).text)
+	require.Equal(t, "*sets.Set[T]", qualifyTypes(set, "pkg", "sets").text)
+}
+
+func TestStructTags(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_struct_tags"), Options{})
+	require.NoError(t, err)
+	lines := moduleAPI(t, "testdata/test_struct_tags", Options{})
+	require.Contains(t, lines, "pkg test_struct_tags, type Widget struct, ID string")
+	diagnostics := map[string][]string{}
+	for _, d := range review.Diagnostics {
+		diagnostics[d.TargetID] = append(diagnostics[d.TargetID], d.Text)
+	}
+	require.Equal(t, map[string][]string{
+		"Label-test_struct_tags.Gadget": {missingJSONTag + "Label"},
+		"test_struct_tags.Gadget":       {conflictingWireNames + `json "kind" (Kind, Type)`},
+		"Value-test_struct_tags.Custom": {ignoredTag + "json tag of Value (Custom has methods MarshalJSON and UnmarshalJSON)"},
+		// unmarshaling uses the tags of a type customizing only marshaling
+		"Label-test_struct_tags.Outgoing": {missingJSONTag + "Label"},
+		"Value-test_struct_tags.Outgoing": {ignoredTag + "json tag of Value when marshaling (Outgoing has method MarshalJSON)"},
+	}, diagnostics)
+
+	review, err = createReview(filepath.Clean("testdata/test_struct_tags"), Options{StructTags: true})
+	require.NoError(t, err)
+	lines = moduleAPI(t, "testdata/test_struct_tags", Options{StructTags: true})
+	for _, line := range []string{
+		"pkg test_struct_tags, type Widget struct, ID string `json:\"id\"`",
+		"pkg test_struct_tags, type Widget struct, Name string `json:\"name,omitempty\" xml:\"name\"`",
+		"pkg test_struct_tags, type Widget struct, Notes string `json:\"-\"`",
+		"pkg test_struct_tags, type Gadget struct, Label string",
+		"pkg test_struct_tags, type Options struct, Retries int",
+	} {
+		require.Contains(t, lines, line)
+	}
+}
+
+func TestOrdering(t *testing.T) {
+	// definitions returns the names of the declarations the review defines, in order
+	definitions := func(review PackageReview) []string {
+		names := []string{}
+		for _, token := range review.Tokens {
+			if token.DefinitionID != nil && token.Kind != TokenTypeLineIDMarker && !strings.HasPrefix(*token.DefinitionID, "Color-") {
+				names = append(names, token.Value)
+			}
+		}
+		return names
+	}
+	for _, test := range []struct {
+		order    Ordering
+		expected []string
+	}{
+		{
+			order:    OrderingKind,
+			expected: []string{"test_ordering", "Painter", "Paint", "Widget", "NewWidget", "Paint", "Zoom", "DefaultWidget", "Color", "Blue", "Red", "PossibleColorValues", "MaxWidgets", "Build"},
+		},
+		{
+			order:    OrderingAlpha,
+			expected: []string{"test_ordering", "Build", "Color", "Blue", "Red", "PossibleColorValues", "MaxWidgets", "Painter", "Paint", "Widget", "NewWidget", "Paint", "Zoom", "DefaultWidget"},
+		},
+		{
+			order:    OrderingSource,
+			expected: []string{"test_ordering", "Color", "Red", "Blue", "PossibleColorValues", "MaxWidgets", "Painter", "Paint", "Widget", "NewWidget", "Zoom", "Paint", "DefaultWidget", "Build"},
+		},
+	} {
+		t.Run(string(test.order), func(t *testing.T) {
+			review, err := createReview(filepath.Clean("testdata/test_ordering"), Options{Order: test.order})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, definitions(review))
+			// the same exports are listed in any order
+			require.Len(t, review.Navigation[0].ChildItems, 5)
+			for i := 0; i < 5; i++ {
+				again, err := createReview(filepath.Clean("testdata/test_ordering"), Options{Order: test.order})
+				require.NoError(t, err)
+				require.Equal(t, review.Tokens, again.Tokens)
+			}
+		})
+	}
+	_, err := createReview(filepath.Clean("testdata/test_ordering"), Options{Order: "random"})
+	require.Error(t, err)
+}
+
+func TestStrayPackages(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_stray_packages"), Options{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"pkg test_stray_packages, type Client struct"}, moduleAPI(t, "testdata/test_stray_packages", Options{}))
+	require.Equal(t, []Diagnostic{
+		{
+			DiagnosticID: "SkippedPackage",
+			HelpLinkURI:  rulesHelpURI + "skippedpackage",
+			Level:        DiagnosticLevelWarning,
+			TargetID:     "test_stray_packages",
+			Text:         skippedPackage + "package main (generate.go)",
+		},
+		{
+			DiagnosticID: "SkippedPackage",
+			HelpLinkURI:  rulesHelpURI + "skippedpackage",
+			Level:        DiagnosticLevelWarning,
+			TargetID:     "test_stray_packages",
+			Text:         skippedPackage + "package main (main.go) in cli",
+		},
+		{
+			DiagnosticID: "SkippedPackage",
+			HelpLinkURI:  rulesHelpURI + "skippedpackage",
+			Level:        DiagnosticLevelWarning,
+			TargetID:     "test_stray_packages",
+			Text:         skippedPackage + "package main (main.go) in gen",
+		},
+		{
+			DiagnosticID: "SkippedPackage",
+			HelpLinkURI:  rulesHelpURI + "skippedpackage",
+			Level:        DiagnosticLevelWarning,
+			TargetID:     "test_stray_packages",
+			Text:         skippedPackage + "package main_test (tool.go) in gen",
+		},
+		{
+			DiagnosticID: "SkippedPackage",
+			HelpLinkURI:  rulesHelpURI + "skippedpackage",
+			Level:        DiagnosticLevelWarning,
+			TargetID:     "test_stray_packages",
+			Text:         skippedPackage + "package test_stray_packages_test (example_helpers.go)",
+		},
+	}, review.Diagnostics)
+}
@@ -0,0 +1,311 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// platform is a GOOS/GOARCH pair
+type platform struct {
+	goos, goarch string
+}
+
+// platforms lists the platforms whose APIs are unioned when no GOOS or GOARCH is specified. It's the
+// output of "go tool dist list" excluding ports that are broken or can't build ordinary packages.
+var platforms = []platform{
+	{"aix", "ppc64"},
+	{"android", "386"}, {"android", "amd64"}, {"android", "arm"}, {"android", "arm64"},
+	{"darwin", "amd64"}, {"darwin", "arm64"},
+	{"dragonfly", "amd64"},
+	{"freebsd", "386"}, {"freebsd", "amd64"}, {"freebsd", "arm"}, {"freebsd", "arm64"}, {"freebsd", "riscv64"},
+	{"illumos", "amd64"},
+	{"ios", "amd64"}, {"ios", "arm64"},
+	{"js", "wasm"},
+	{"linux", "386"}, {"linux", "amd64"}, {"linux", "arm"}, {"linux", "arm64"}, {"linux", "loong64"},
+	{"linux", "mips"}, {"linux", "mips64"}, {"linux", "mips64le"}, {"linux", "mipsle"}, {"linux", "ppc64"},
+	{"linux", "ppc64le"}, {"linux", "riscv64"}, {"linux", "s390x"},
+	{"netbsd", "386"}, {"netbsd", "amd64"}, {"netbsd", "arm"}, {"netbsd", "arm64"},
+	{"openbsd", "386"}, {"openbsd", "amd64"}, {"openbsd", "arm"}, {"openbsd", "arm64"}, {"openbsd", "ppc64"},
+	{"openbsd", "riscv64"},
+	{"plan9", "386"}, {"plan9", "amd64"}, {"plan9", "arm"},
+	{"solaris", "amd64"},
+	{"wasip1", "wasm"},
+	{"windows", "386"}, {"windows", "amd64"}, {"windows", "arm"}, {"windows", "arm64"},
+}
+
+// knownOS and knownArch are the GOOS and GOARCH values the go command recognizes in file names e.g. "conn_windows.go"
+var (
+	knownOS = []string{"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js", "linux",
+		"nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos"}
+	knownArch = []string{"386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips", "mipsle",
+		"mips64", "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64", "s390", "s390x",
+		"sparc", "sparc64", "wasm"}
+	// unixOS lists the GOOS values satisfying the "unix" build constraint
+	unixOS = []string{"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "linux", "netbsd",
+		"openbsd", "solaris"}
+)
+
+// satisfies returns true when the platform satisfies a build tag. Release tags such as "go1.21" and
+// the "gc" compiler tag are always satisfied; other tags are satisfied only when they're in tags.
+func (p platform) satisfies(tag string, tags []string) bool {
+	switch {
+	case tag == p.goos, tag == p.goarch:
+		return true
+	case tag == "linux" && p.goos == "android", tag == "solaris" && p.goos == "illumos", tag == "darwin" && p.goos == "ios":
+		return true
+	case tag == "unix":
+		return slices.Contains(unixOS, p.goos)
+	case tag == "gc", strings.HasPrefix(tag, "go1."):
+		return true
+	}
+	return slices.Contains(tags, tag)
+}
+
+// platformSet is a set of platforms, having bit i set when it includes the buildConfig's i-th platform
+type platformSet uint64
+
+// buildConfig selects the files of a package to index by their build constraints. A package's API is
+// the union of its APIs on the configured platforms.
+type buildConfig struct {
+	platforms []platform
+	tags      []string
+}
+
+// newBuildConfig returns the build configuration for the given options. When GOOS or GOARCH is set, the
+// configuration includes only the matching platforms, or the specified platform when none match.
+func newBuildConfig(opts Options) buildConfig {
+	bc := buildConfig{tags: opts.Tags}
+	for _, p := range platforms {
+		if (opts.GOOS == "" || opts.GOOS == p.goos) && (opts.GOARCH == "" || opts.GOARCH == p.goarch) {
+			bc.platforms = append(bc.platforms, p)
+		}
+	}
+	if len(bc.platforms) == 0 {
+		bc.platforms = []platform{{opts.GOOS, opts.GOARCH}}
+	}
+	return bc
+}
+
+// all returns the set of all the configuration's platforms
+func (bc buildConfig) all() platformSet {
+	return platformSet(1)<<len(bc.platforms) - 1
+}
+
+// match returns the set of platforms satisfying a build constraint. A nil constraint is satisfied by all platforms.
+func (bc buildConfig) match(expr constraint.Expr) platformSet {
+	if expr == nil {
+		return bc.all()
+	}
+	set := platformSet(0)
+	for i, p := range bc.platforms {
+		if expr.Eval(func(tag string) bool { return p.satisfies(tag, bc.tags) }) {
+			set |= 1 << i
+		}
+	}
+	return set
+}
+
+// includeFile returns true when a file in dir builds on any of the configuration's platforms. It's a filter for
+// parser.ParseDir, so it returns true for files it can't parse, leaving ParseDir to report the error.
+func (bc buildConfig) includeFile(dir, name string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return true
+	}
+	return bc.match(fileConstraint(name, f)) != 0
+}
+
+// fileConstraint returns a file's build constraint, combining its //go:build line (or, lacking that, its
+// // +build lines) with the constraint implied by its name e.g. "windows" for "conn_windows.go". It
+// returns nil for files without constraints.
+func fileConstraint(name string, f *ast.File) constraint.Expr {
+	var goBuild, plusBuild constraint.Expr
+	for _, cg := range f.Comments {
+		if cg.Pos() >= f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				continue
+			}
+			if constraint.IsGoBuild(c.Text) {
+				goBuild = expr
+			} else {
+				plusBuild = and(plusBuild, expr)
+			}
+		}
+	}
+	expr := goBuild
+	if expr == nil {
+		expr = plusBuild
+	}
+	return and(fileNameConstraint(name), expr)
+}
+
+// fileNameConstraint returns the build constraint implied by a file's name, following the go command's
+// rules: the name's last one or two "_" separated elements, excluding the first element, can be a GOOS
+// and/or GOARCH. It returns nil for names implying no constraint.
+func fileNameConstraint(name string) constraint.Expr {
+	name = strings.TrimSuffix(name, ".go")
+	name = strings.TrimSuffix(name, "_test")
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return nil
+	}
+	l := strings.Split(name[i:], "_")
+	n := len(l)
+	if n >= 2 && slices.Contains(knownOS, l[n-2]) && slices.Contains(knownArch, l[n-1]) {
+		return and(&constraint.TagExpr{Tag: l[n-2]}, &constraint.TagExpr{Tag: l[n-1]})
+	}
+	if n >= 1 && (slices.Contains(knownOS, l[n-1]) || slices.Contains(knownArch, l[n-1])) {
+		return &constraint.TagExpr{Tag: l[n-1]}
+	}
+	return nil
+}
+
+// and returns the conjunction of two constraints, either of which may be nil
+func and(x, y constraint.Expr) constraint.Expr {
+	if x == nil {
+		return y
+	}
+	if y == nil {
+		return x
+	}
+	return &constraint.AndExpr{X: x, Y: y}
+}
+
+// platformVariant is a declaration of an export in a file that builds only on some platforms
+type platformVariant struct {
+	// label is the file's build constraint e.g. "windows"
+	label string
+	set   platformSet
+	// text is the declaration's rendering, excluding comments, for comparing variants
+	text string
+}
+
+// mergePlatformExports adds exports declared in a platform specific file to dst, recording a variant for each
+// exported declaration. Declarations already in dst without variants were declared in files that build on all
+// platforms. When several variants declare an export, dst gets the first.
+func mergePlatformExports[T TokenMaker](dst, src map[string]T, label string, set platformSet, variants map[string][]platformVariant) {
+	for k, v := range src {
+		existing, declared := dst[k]
+		if !v.Exported() {
+			if !declared {
+				dst[k] = v
+			}
+			continue
+		}
+		if declared && len(variants[existing.ID()]) == 0 {
+			continue
+		}
+		variants[v.ID()] = append(variants[v.ID()], platformVariant{label: label, set: set, text: declarationText(flatTokens(v.MakeLines()))})
+		if !declared {
+			dst[k] = v
+		}
+	}
+}
+
+// mergePlatformContent merges the content of a file that builds only on the given platforms into c
+func (c *content) mergePlatformContent(fc content, label string, set platformSet, variants map[string][]platformVariant) {
+	mergePlatformExports(c.Consts, fc.Consts, label, set, variants)
+	mergePlatformExports(c.Funcs, fc.Funcs, label, set, variants)
+	mergePlatformExports(c.Interfaces, fc.Interfaces, label, set, variants)
+	mergePlatformExports(c.SimpleTypes, fc.SimpleTypes, label, set, variants)
+	mergePlatformExports(c.Structs, fc.Structs, label, set, variants)
+	mergePlatformExports(c.Vars, fc.Vars, label, set, variants)
+	for k, v := range fc.constExprs {
+		if _, ok := c.constExprs[k]; !ok {
+			c.constExprs[k] = v
+		}
+	}
+	for k, v := range fc.varCalls {
+		if _, ok := c.varCalls[k]; !ok {
+			c.varCalls[k] = v
+		}
+	}
+}
+
+// declarationText returns the text of a declaration's tokens excluding comments
+func declarationText(tokens []Token) string {
+	sb := strings.Builder{}
+	for _, t := range tokens {
+		if t.Kind != TokenTypeComment {
+			sb.WriteString(t.Value)
+		}
+	}
+	return sb.String()
+}
+
+// platformAvailability describes the platforms declaring an export
+type platformAvailability struct {
+	// on lists the build constraints of the files declaring the export e.g. "unix, windows"
+	on string
+	// partial is true when the export isn't available on all platforms
+	partial bool
+	// differ is true when the export's declaration differs by platform
+	differ bool
+}
+
+// annotatePlatformVariants records the platforms declaring each export declared in platform specific files,
+// and adds a platform note for each export that isn't available on all platforms or whose declaration differs
+// by platform
+func (p *Pkg) annotatePlatformVariants(variants map[string][]platformVariant) {
+	all := p.build.all()
+	for id, vs := range variants {
+		union := platformSet(0)
+		labels := []string{}
+		texts := map[string]bool{}
+		for _, v := range vs {
+			union |= v.set
+			if !slices.Contains(labels, v.label) {
+				labels = append(labels, v.label)
+			}
+			texts[v.text] = true
+		}
+		sort.Strings(labels)
+		on := strings.Join(labels, ", ")
+		note := "// Platforms: " + on
+		p.platformExports[id] = platformAvailability{on: on, partial: union != all, differ: len(texts) > 1}
+		if len(texts) > 1 {
+			note += " (declarations differ)"
+		} else if union == all {
+			// the export is declared identically for all platforms, albeit in several files
+			continue
+		}
+		p.platformNotes[id] = note
+	}
+}
+
+// insertPlatformNotes inserts a comment line before each line defining an ID having a platform note
+func insertPlatformNotes(lines []tokenLine, notes map[string]string) []tokenLine {
+	if len(notes) == 0 {
+		return lines
+	}
+	result := make([]tokenLine, 0, len(lines))
+	for _, l := range lines {
+		for _, t := range l.tokens {
+			if t.DefinitionID == nil {
+				continue
+			}
+			if note, ok := notes[*t.DefinitionID]; ok {
+				delete(notes, *t.DefinitionID)
+				list := &[]Token{}
+				makeToken(nil, nil, note, TokenTypeComment, list)
+				result = append(result, tokenLine{tokens: *list})
+			}
+		}
+		l.children = insertPlatformNotes(l.children, notes)
+		result = append(result, l)
+	}
+	return result
+}
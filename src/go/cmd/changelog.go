@@ -0,0 +1,94 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog <baseModuleDir> <moduleDir>",
+	Short: "Writes a CHANGELOG section describing a module's API changes",
+	Long: `changelog compares the exported API of two versions of a module and writes a
+markdown CHANGELOG section grouping the changes under "Features Added", "Breaking Changes"
+and "Other Changes". The section's version is the one declared by the module's moduleVersion
+constant unless the --version flag specifies another.`,
+	Args:          cobra.ExactArgs(2),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		base, err := NewModule(args[0], Options{})
+		if err != nil {
+			return err
+		}
+		m, err := NewModule(args[1], Options{})
+		if err != nil {
+			return err
+		}
+		version := changelogVersion
+		if version == "" {
+			version = moduleVersion(m)
+		}
+		var w io.Writer = os.Stdout
+		if changelogOutput != "" {
+			f, err := os.Create(changelogOutput)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		writeChangelog(w, version, diffAPIs(base, m))
+		return nil
+	},
+}
+
+var changelogOutput, changelogVersion string
+
+func init() {
+	changelogCmd.Flags().StringVarP(&changelogOutput, "output", "o", "", "file to write instead of stdout")
+	changelogCmd.Flags().StringVar(&changelogVersion, "version", "", "version of the changelog section")
+	rootCmd.AddCommand(changelogCmd)
+}
+
+// writeChangelog writes a markdown CHANGELOG section for the changes in d. Sections having no changes are omitted.
+func writeChangelog(w io.Writer, version string, d APIDiff) {
+	version = strings.TrimPrefix(version, "v")
+	if version == "" {
+		version = "Unreleased"
+	} else {
+		version += " (Unreleased)"
+	}
+	fmt.Fprintf(w, "## %s\n", version)
+	// changes are qualified by package only when the module has more than one package
+	pkgs := map[string]bool{}
+	for _, c := range d.Changes {
+		pkgs[c.Package] = true
+	}
+	for _, section := range []struct {
+		heading string
+		changes []Change
+	}{
+		{"Features Added", d.Additive()},
+		{"Breaking Changes", d.Breaking()},
+		{"Other Changes", d.Other()},
+	} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n### %s\n\n", section.heading)
+		for _, c := range section.changes {
+			if len(pkgs) > 1 {
+				fmt.Fprintf(w, "- `%s`: %s\n", c.Package, c.Text)
+			} else {
+				fmt.Fprintf(w, "- %s\n", c.Text)
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteChangelog(t *testing.T) {
+	d, err := diffModules(filepath.Clean("testdata/test_diff/old/widgets"), filepath.Clean("testdata/test_diff/new/widgets"))
+	require.NoError(t, err)
+	buf := bytes.Buffer{}
+	writeChangelog(&buf, "v1.1.0", d)
+	require.Equal(t, "## 1.1.0 (Unreleased)\n"+
+		"\n### Features Added\n\n"+
+		"- New field `Bar` in struct `BazOptions`\n"+
+		"- New function `NewFooClient() *Client`\n"+
+		"- New value `ColorGreen` added to enum type `Color`\n"+
+		"\n### Breaking Changes\n\n"+
+		"- Function `*Client.Delete` has been removed\n"+
+		"- Struct `Removed` has been removed\n"+
+		"- Function `*Client.Get` parameter(s) have been changed from `(context.Context, string)` to `(context.Context, string, int)`\n"+
+		"- Type of `BazOptions.Count` has been changed from `*string` to `*int32`\n"+
+		"- Underlying type of `Mode` has been changed from `int` to `string`\n"+
+		"- Value of const `ColorBlue` has been changed from `\"blue\"` to `\"navy\"`\n"+
+		"- New method `Perimeter` in interface `Shape`\n"+
+		"\n### Other Changes\n\n"+
+		"- Const `MaxSize` has been deprecated\n", buf.String())
+
+	buf.Reset()
+	writeChangelog(&buf, "", APIDiff{Changes: []Change{
+		{Kind: ChangeKindAdded, Package: "widgets", Text: "New function `Foo`"},
+		{Kind: ChangeKindAdded, Package: "widgets/sub", Text: "New function `Bar`"},
+	}})
+	require.Equal(t, "## Unreleased\n\n### Features Added\n\n- `widgets`: New function `Foo`\n- `widgets/sub`: New function `Bar`\n", buf.String())
+}
@@ -0,0 +1,278 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// apiFileName is the name of the API baseline file in a module's directory
+const apiFileName = "api.txt"
+
+var checkCmd = &cobra.Command{
+	Use:   "check <moduleDir>",
+	Short: "Checks a module's API against its committed baseline",
+	Long: `check regenerates a module's API listing, one exported declaration per line, and
+compares it to the baseline file committed in the module's directory (` + apiFileName + ` by
+default). It fails with a diff when the API has drifted from the baseline. Use --update
+to write the current API to the baseline file.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		baseline := checkBaseline
+		if baseline == "" {
+			baseline = filepath.Join(args[0], apiFileName)
+		}
+		m, err := NewModule(args[0], Options{})
+		if err != nil {
+			return err
+		}
+		lines := apiLines(m)
+		if checkUpdate {
+			return os.WriteFile(baseline, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+		}
+		removed, added, err := checkAPI(lines, baseline)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("no API baseline at %s; run check with --update to create it", baseline)
+		} else if err != nil {
+			return err
+		}
+		if len(removed)+len(added) == 0 {
+			return nil
+		}
+		for _, l := range removed {
+			fmt.Println("-" + l)
+		}
+		for _, l := range added {
+			fmt.Println("+" + l)
+		}
+		return fmt.Errorf("API has drifted from %s; run check with --update to accept the changes", baseline)
+	},
+}
+
+var checkBaseline string
+var checkUpdate bool
+
+func init() {
+	checkCmd.Flags().StringVar(&checkBaseline, "baseline", "", "path of the baseline file (default <moduleDir>/"+apiFileName+")")
+	checkCmd.Flags().BoolVar(&checkUpdate, "update", false, "write the current API to the baseline file")
+	rootCmd.AddCommand(checkCmd)
+}
+
+// checkAPI compares API lines to the baseline file, returning the lines missing from and not in the baseline
+func checkAPI(lines []string, baseline string) (removed, added []string, err error) {
+	b, err := os.ReadFile(baseline)
+	if err != nil {
+		return nil, nil, err
+	}
+	want := map[string]bool{}
+	for _, l := range strings.Split(string(b), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			want[l] = true
+		}
+	}
+	have := map[string]bool{}
+	for _, l := range lines {
+		have[l] = true
+		if !want[l] {
+			added = append(added, l)
+		}
+	}
+	for l := range want {
+		if !have[l] {
+			removed = append(removed, l)
+		}
+	}
+	slices.Sort(removed)
+	return removed, added, nil
+}
+
+// apiLines lists a module's exported declarations, one per line, in the style of Go's api/go1.*.txt files.
+// Members of structs and interfaces are listed with the declaration of their type e.g. "pkg widgets, type
+// Widget struct, Name string". Signatures have types but no receiver, parameter or result names, and neither
+// have func types anywhere in a line, because renaming those doesn't change the API. Declarations having a
+// deprecation notice end with "//deprecated". The lines are sorted.
+func apiLines(m *Module) []string {
+	lines := []string{}
+	for name, p := range publicPackages(m) {
+		add := func(text string, doc []string) {
+			if _, deprecated := deprecationNotice(doc); deprecated {
+				text += " //deprecated"
+			}
+			lines = append(lines, "pkg "+name+", "+text)
+		}
+		for _, s := range p.c.Structs {
+			if !s.Exported() {
+				continue
+			}
+			header := "type " + s.name + apiTypeParams(s.typeParamNames, s.typeParamConstraints) + " struct"
+			add(header, s.doc)
+			for _, e := range s.AnonymousFields {
+				if exportedFieldRgx.MatchString(e.text) {
+					add(header+", embedded "+apiType(e), nil)
+				}
+			}
+			for field, t := range s.fields {
+				if !exportedFieldRgx.MatchString(field) {
+					continue
+				}
+				text := header + ", " + field + " " + apiType(t)
+				if tag, ok := s.fieldTags[field]; ok && s.showTags {
+					text += " " + tag
+				}
+				add(text, s.fieldDocs[field])
+			}
+		}
+		for _, i := range p.c.Interfaces {
+			if !i.Exported() {
+				continue
+			}
+			header := "type " + i.name + apiTypeParams(i.typeParamNames, i.typeParamConstraints) + " interface"
+			add(header, i.doc)
+			for _, e := range i.embeddedInterfaces {
+				if exportedFieldRgx.MatchString(e.text) {
+					add(header+", embedded "+apiType(e), nil)
+				}
+			}
+			for _, union := range i.typeSet {
+				terms := make([]string, len(union))
+				for j, term := range union {
+					terms[j] = apiType(term.typ)
+					if term.tilde {
+						terms[j] = "~" + terms[j]
+					}
+				}
+				add(header+", "+strings.Join(terms, " | "), nil)
+			}
+			for name, method := range i.methods {
+				if unicode.IsUpper(rune(name[0])) {
+					add(header+", "+name+apiSignature(method), method.doc)
+				}
+			}
+		}
+		for _, t := range p.c.SimpleTypes {
+			if t.Exported() {
+				add("type "+t.name+apiTypeParams(t.typeParamNames, t.typeParamConstraints)+" "+apiType(t.underlyingType), t.doc)
+			}
+		}
+		for _, f := range p.c.Funcs {
+			if !f.Exported() || isExampleOrTest(f.Name()) {
+				continue
+			}
+			if f.ReceiverType == "" {
+				add("func "+f.Name()+apiSignature(f), f.doc)
+			} else {
+				add("method ("+f.ReceiverType+") "+f.Name()+apiSignature(f), f.doc)
+			}
+		}
+		for _, kind := range []string{"const", "var"} {
+			for name, d := range p.c.declarations(kind) {
+				if r := rune(name[0]); r == '_' || !unicode.IsUpper(r) {
+					continue
+				}
+				text := kind + " " + name
+				if t := d.Type.text; t != "" && t != skip {
+					text += " " + apiType(d.Type)
+				}
+				if d.value != skip {
+					text += " = " + d.value
+				}
+				add(text, d.doc)
+			}
+		}
+	}
+	slices.Sort(lines)
+	return slices.Compact(lines)
+}
+
+// apiSignature returns a func's signature as apiLines lists it, like signature but omitting the names of func
+// types' parameters and results
+func apiSignature(f Func) string {
+	params := make([]string, len(f.paramTypes))
+	for i, t := range f.paramTypes {
+		params[i] = apiType(t)
+	}
+	sig := apiTypeParams(f.typeParamNames, f.typeParamConstraints) + "(" + strings.Join(params, ", ") + ")"
+	returns := make([]string, len(f.Returns))
+	for i, t := range f.Returns {
+		returns[i] = apiType(t)
+	}
+	switch len(returns) {
+	case 0:
+	case 1:
+		sig += " " + returns[0]
+	default:
+		sig += " (" + strings.Join(returns, ", ") + ")"
+	}
+	return sig
+}
+
+// apiTypeParams is typeParamList for apiLines, whose constraints are formatted by apiType
+func apiTypeParams(names []string, constraints []typeExpr) string {
+	unnamed := make([]typeExpr, len(constraints))
+	for i, c := range constraints {
+		unnamed[i] = typeExpr{text: apiType(c)}
+	}
+	return typeParamList(names, unnamed)
+}
+
+// apiType returns the text of a type as apiLines lists it, omitting the parameter and result names of func types,
+// including func types nested in other types e.g. "func(Event, int) (bool, error)" for
+// "func(ctx Event, retries int) (handled bool, err error)"
+func apiType(t typeExpr) string {
+	text, variadic := strings.CutPrefix(t.text, "...")
+	expr, err := parser.ParseExpr(text)
+	if err != nil {
+		return t.text
+	}
+	named := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if ft, ok := n.(*ast.FuncType); ok {
+			named = unnameFields(ft.Params) || named
+			named = unnameFields(ft.Results) || named
+		}
+		return true
+	})
+	if !named {
+		return t.text
+	}
+	unnamed, ok := exprType(expr, func(ast.Expr) string { return "" })
+	if !ok {
+		return t.text
+	}
+	if variadic {
+		return "..." + unnamed.text
+	}
+	return unnamed.text
+}
+
+// unnameFields replaces the fields of a parameter or result list with one unnamed field per name, returning
+// true when any field had a name
+func unnameFields(fl *ast.FieldList) bool {
+	if fl == nil {
+		return false
+	}
+	named := false
+	list := []*ast.Field{}
+	for _, f := range fl.List {
+		named = named || len(f.Names) > 0
+		for i := 0; i < max(len(f.Names), 1); i++ {
+			list = append(list, &ast.Field{Type: f.Type})
+		}
+	}
+	fl.List = list
+	return named
+}
@@ -0,0 +1,108 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPILines(t *testing.T) {
+	m, err := NewModule(filepath.Clean("testdata/test_deprecated"), Options{})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`pkg test_deprecated, const NewColor = "new"`,
+		`pkg test_deprecated, const OldColor = "old" //deprecated`,
+		"pkg test_deprecated, func NotDeprecated()",
+		"pkg test_deprecated, func OldFunc() //deprecated",
+		"pkg test_deprecated, method (*Gadget) Run()",
+		"pkg test_deprecated, method (*Widget) Run() //deprecated",
+		"pkg test_deprecated, type Gadget struct",
+		"pkg test_deprecated, type Shape interface",
+		"pkg test_deprecated, type Shape interface, Area() float64 //deprecated",
+		"pkg test_deprecated, type Widget struct //deprecated",
+		"pkg test_deprecated, type Widget struct, Name string",
+		"pkg test_deprecated, type Widget struct, Size int //deprecated",
+	}, apiLines(m))
+
+	// renaming parameters and receivers doesn't change the API
+	m, err = NewModule(filepath.Clean("testdata/test_struct"), Options{})
+	require.NoError(t, err)
+	for _, l := range apiLines(m) {
+		if strings.Contains(l, "method (") {
+			require.NotRegexp(t, `\(\w+ \*?\w+\)`, l, "receiver names should be omitted")
+		}
+	}
+}
+
+func TestCheckAPI(t *testing.T) {
+	m, err := NewModule(filepath.Clean("testdata/test_struct"), Options{})
+	require.NoError(t, err)
+	lines := apiLines(m)
+	baseline := filepath.Join(t.TempDir(), apiFileName)
+
+	_, _, err = checkAPI(lines, baseline)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(baseline, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+	removed, added, err := checkAPI(lines, baseline)
+	require.NoError(t, err)
+	require.Empty(t, removed)
+	require.Empty(t, added)
+
+	// the baseline lacks the first line and has a line the API lacks
+	drifted := append([]string{"pkg test_struct, func Removed()"}, lines[1:]...)
+	require.NoError(t, os.WriteFile(baseline, []byte(strings.Join(drifted, "\n")+"\n"), 0644))
+	removed, added, err = checkAPI(lines, baseline)
+	require.NoError(t, err)
+	require.Equal(t, []string{"pkg test_struct, func Removed()"}, removed)
+	require.Equal(t, []string{lines[0]}, added)
+}
+
+func TestCheckAPIRenamedParameters(t *testing.T) {
+	// copy the module, so its parameters can be renamed
+	dir := filepath.Join(t.TempDir(), "test_type_tokens")
+	require.NoError(t, filepath.WalkDir("testdata/test_type_tokens", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel("testdata/test_type_tokens", path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dir, rel), 0755)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, rel), b, 0644)
+	}))
+	baseline := filepath.Join(dir, apiFileName)
+	require.NoError(t, os.WriteFile(baseline, []byte(strings.Join(moduleAPI(t, dir, Options{}), "\n")+"\n"), 0644))
+
+	src := filepath.Join(dir, "test.go")
+	b, err := os.ReadFile(src)
+	require.NoError(t, err)
+	renamed := strings.Replace(string(b), "func(ctx Event, retries int) (handled bool, err error)", "func(e Event, attempts int) (ok bool, failure error)", 1)
+	require.NotEqual(t, string(b), renamed)
+	require.NoError(t, os.WriteFile(src, []byte(renamed), 0644))
+	removed, added, err := checkAPI(moduleAPI(t, dir, Options{}), baseline)
+	require.NoError(t, err)
+	require.Empty(t, removed)
+	require.Empty(t, added)
+}
+
+// moduleAPI returns the API lines of the module in dir
+func moduleAPI(t *testing.T, dir string, o Options) []string {
+	m, err := NewModule(filepath.Clean(dir), o)
+	require.NoError(t, err)
+	return apiLines(m)
+}
@@ -0,0 +1,289 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+)
+
+// constExpr is the expression defining a const, which may be implicitly repeated from a previous spec of its block
+type constExpr struct {
+	expr  ast.Expr
+	ident *ast.Ident
+	// imports maps the names of the packages imported by the const's file to their import paths
+	imports map[string]string
+	// iota is the index of the const's spec in its block
+	iota int64
+}
+
+// constEvaluator evaluates the package's const expressions with go/constant
+type constEvaluator struct {
+	exprs map[string]constExpr
+	// evaluating holds the names of consts being evaluated, to guard against invalid cyclic definitions
+	evaluating map[string]bool
+	info       *types.Info
+	// typeDefs are the definitions of the package's types, which tell the sizes of named unsigned types
+	typeDefs map[string]typeDef
+	values   map[string]constant.Value
+}
+
+// evaluateConsts sets the value of each const declaration to its constant value, and its expr to its
+// source expression when that differs from the value, e.g. "KB = 1024 // 1 << 10". Consts whose values
+// can't be determined, for example because they refer to consts of other packages, keep their source
+// expression as their value. Type checked packages use the values computed by go/types.
+func (c *content) evaluateConsts(pkg Pkg) {
+	e := constEvaluator{exprs: c.constExprs, evaluating: map[string]bool{}, info: pkg.info, typeDefs: pkg.types, values: map[string]constant.Value{}}
+	for name, ce := range c.constExprs {
+		decl, ok := c.Consts[name]
+		if !ok {
+			continue
+		}
+		v := e.value(name)
+		if v == nil {
+			continue
+		}
+		decl.value = constValueString(v)
+		if expr := getExprValue(pkg, ce.expr); expr != decl.value {
+			decl.expr = expr
+		}
+		c.Consts[name] = decl
+	}
+}
+
+// value returns the constant value of the named const, or nil when it can't be determined
+func (e *constEvaluator) value(name string) constant.Value {
+	if v, ok := e.values[name]; ok {
+		return v
+	}
+	ce, ok := e.exprs[name]
+	if !ok || e.evaluating[name] {
+		return nil
+	}
+	var v constant.Value
+	if e.info != nil {
+		if c, ok := e.info.Defs[ce.ident].(*types.Const); ok && c.Val().Kind() != constant.Unknown {
+			v = c.Val()
+		}
+	}
+	if v == nil {
+		e.evaluating[name] = true
+		v = e.eval(ce.expr, ce)
+		delete(e.evaluating, name)
+	}
+	e.values[name] = v
+	return v
+}
+
+// eval evaluates expr, which is ce's expression or part of it. It returns nil for expressions it can't
+// evaluate, such as references to other packages' consts or calls of builtins other than len.
+func (e *constEvaluator) eval(expr ast.Expr, ce constExpr) constant.Value {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		// const DefaultLinkCredit = 1
+		return known(constant.MakeFromLiteral(x.Value, x.Kind, 0))
+	case *ast.Ident:
+		switch x.Name {
+		case "iota":
+			return constant.MakeInt64(ce.iota)
+		case "true", "false":
+			return constant.MakeBool(x.Name == "true")
+		}
+		return e.value(x.Name)
+	case *ast.ParenExpr:
+		return e.eval(x.X, ce)
+	case *ast.UnaryExpr:
+		// const FooConst = -1
+		v := e.eval(x.X, ce)
+		if v == nil {
+			return nil
+		}
+		switch {
+		case x.Op == token.NOT && v.Kind() == constant.Bool,
+			(x.Op == token.ADD || x.Op == token.SUB) && isNumeric(v):
+			return known(constant.UnaryOp(x.Op, v, 0))
+		case x.Op == token.XOR && v.Kind() == constant.Int:
+			// the complement of an unsigned value depends on its size e.g. ^uint8(0) == 255
+			if bits, ok := e.unsignedBits(x.X); ok {
+				return known(constant.UnaryOp(x.Op, v, bits))
+			}
+		}
+	case *ast.BinaryExpr:
+		// const KB = 1 << 10
+		// const FooConst = "value" + Bar
+		a, b := e.eval(x.X, ce), e.eval(x.Y, ce)
+		if a == nil || b == nil {
+			return nil
+		}
+		return binaryOp(a, x.Op, b)
+	case *ast.CallExpr:
+		// const Timeout = time.Duration(30)
+		// const Version = string("1.0")
+		if len(x.Args) != 1 {
+			return nil
+		}
+		v := e.eval(x.Args[0], ce)
+		if v == nil {
+			return nil
+		}
+		var id *ast.Ident
+		switch fun := x.Fun.(type) {
+		case *ast.Ident:
+			id = fun
+		case *ast.SelectorExpr:
+			// a conversion to a type defined in another package e.g. "time.Duration(30)", unless
+			// it's a call of one of package unsafe's funcs, whose results depend on the platform
+			if pkg, ok := fun.X.(*ast.Ident); !ok || ce.imports[pkg.Name] == "unsafe" {
+				return nil
+			}
+			return v
+		default:
+			return nil
+		}
+		switch {
+		case id.Name == "len":
+			if v.Kind() != constant.String {
+				return nil
+			}
+			return constant.MakeInt64(int64(len(constant.StringVal(v))))
+		case slices.Contains(builtinFuncs, id.Name):
+			// other builtins such as cap, real and imag aren't conversions
+			return nil
+		case id.Name == "string" && v.Kind() == constant.Int:
+			if r, ok := constant.Int64Val(v); ok {
+				return constant.MakeString(string(rune(r)))
+			}
+			return nil
+		case slices.Contains([]string{"float32", "float64"}, id.Name):
+			return known(constant.ToFloat(v))
+		case slices.Contains(internalTypes, id.Name) && isNumeric(v) && !slices.Contains([]string{"complex64", "complex128"}, id.Name):
+			return known(constant.ToInt(v))
+		}
+		// conversion to a type defined in this package
+		return v
+	}
+	return nil
+}
+
+// binaryOp applies a binary operator to constant operands, returning nil when the operands are invalid for the operator
+func binaryOp(a constant.Value, op token.Token, b constant.Value) constant.Value {
+	switch op {
+	case token.SHL, token.SHR:
+		s, ok := constant.Uint64Val(constant.ToInt(b))
+		if a = constant.ToInt(a); !ok || a.Kind() != constant.Int {
+			return nil
+		}
+		return constant.Shift(a, op, uint(s))
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		if !sameKind(a, b) {
+			return nil
+		}
+		ordered := a.Kind() != constant.Bool && a.Kind() != constant.Complex && b.Kind() != constant.Complex
+		if op != token.EQL && op != token.NEQ && !ordered {
+			return nil
+		}
+		return constant.MakeBool(constant.Compare(a, op, b))
+	case token.LAND, token.LOR:
+		if a.Kind() != constant.Bool || b.Kind() != constant.Bool {
+			return nil
+		}
+	case token.ADD:
+		if !sameKind(a, b) || a.Kind() == constant.Bool {
+			return nil
+		}
+	default:
+		if !isNumeric(a) || !isNumeric(b) {
+			return nil
+		}
+		if (op == token.QUO || op == token.REM) && constant.Sign(b) == 0 {
+			return nil
+		}
+		if op == token.QUO && a.Kind() == constant.Int && b.Kind() == constant.Int {
+			// integer division
+			op = token.QUO_ASSIGN
+		}
+		if (op == token.REM || op == token.AND || op == token.OR || op == token.XOR || op == token.AND_NOT) &&
+			(a.Kind() != constant.Int || b.Kind() != constant.Int) {
+			return nil
+		}
+	}
+	return known(constant.BinaryOp(a, op, b))
+}
+
+// sameKind returns true when two constants are of the same kind or are both numeric
+func sameKind(a, b constant.Value) bool {
+	return a.Kind() == b.Kind() || isNumeric(a) && isNumeric(b)
+}
+
+// builtinFuncs are the names of Go's predeclared funcs
+var builtinFuncs = []string{"append", "cap", "clear", "close", "complex", "copy", "delete", "imag", "len", "make", "max", "min", "new", "panic", "print", "println", "real", "recover"}
+
+// unsignedBits returns the size of an expression converted to an unsigned type e.g. 8 for "uint8(0)" and for
+// "Flags(0)" given "type Flags uint8", or 0 for other expressions. It returns false when expr is converted to a
+// type whose underlying type isn't known, such as a type defined in another package.
+func (e *constEvaluator) unsignedBits(expr ast.Expr) (uint, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return 0, true
+	}
+	id, ok := call.Fun.(*ast.Ident)
+	if !ok {
+		return 0, false
+	}
+	name := id.Name
+	// follow definitions of named types to their predeclared underlying type, at most as many as the package defines
+	for i := 0; i <= len(e.typeDefs); i++ {
+		if slices.Contains(internalTypes, name) {
+			break
+		}
+		def, ok := e.typeDefs[name]
+		if !ok {
+			return 0, false
+		}
+		underlying, ok := def.n.Type.(*ast.Ident)
+		if !ok {
+			return 0, false
+		}
+		name = underlying.Name
+	}
+	switch name {
+	case "uint8", "byte":
+		return 8, true
+	case "uint16":
+		return 16, true
+	case "uint32":
+		return 32, true
+	case "uint", "uint64", "uintptr":
+		return 64, true
+	}
+	return 0, slices.Contains(internalTypes, name)
+}
+
+func isNumeric(v constant.Value) bool {
+	switch v.Kind() {
+	case constant.Int, constant.Float, constant.Complex:
+		return true
+	}
+	return false
+}
+
+// known returns nil for unknown values, which is how go/constant reports invalid operations
+func known(v constant.Value) constant.Value {
+	if v.Kind() == constant.Unknown {
+		return nil
+	}
+	return v
+}
+
+// constValueString formats a constant value as Go source
+func constValueString(v constant.Value) string {
+	if v.Kind() == constant.String {
+		// String truncates long strings
+		return v.ExactString()
+	}
+	return v.String()
+}
@@ -7,8 +7,8 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"os"
 	"regexp"
-	"sort"
 	"strings"
 	"unicode"
 )
@@ -37,6 +37,16 @@ type content struct {
 	Structs map[string]Struct `json:"structs,omitempty"`
 
 	Vars map[string]Declaration
+
+	// varCalls maps the names of vars initialized by func calls to those calls, for resolving the vars' types after indexing
+	varCalls map[string]varCall
+
+	// constExprs maps the names of consts to their defining expressions, for evaluation after indexing
+	constExprs map[string]constExpr
+
+	// promoted maps the names of structs and interfaces to the members their embedded types promote.
+	// It's empty unless promoted members have been resolved.
+	promoted map[string][]promotedMember
 }
 
 // newContent returns an initialized Content object.
@@ -48,6 +58,9 @@ func newContent() content {
 		SimpleTypes: make(map[string]SimpleType),
 		Structs:     make(map[string]Struct),
 		Vars:        make(map[string]Declaration),
+		constExprs:  make(map[string]constExpr),
+		varCalls:    make(map[string]varCall),
+		promoted:    make(map[string][]promotedMember),
 	}
 }
 
@@ -56,26 +69,45 @@ func (c content) isEmpty() bool {
 	return len(c.Consts)+len(c.Funcs)+len(c.Interfaces)+len(c.SimpleTypes)+len(c.Structs)+len(c.Vars) == 0
 }
 
-// addGenDecl adds const and var declaration to the exports list
+// addGenDecl adds the consts or vars declared by a const or var declaration to the exports list. Specs
+// of a const block lacking a type and values implicitly repeat those of the previous spec.
 // The imports map stores the key value pair for package imports which will be used to identify types.
-func (c *content) addGenDecl(pkg Pkg, tok token.Token, vs *ast.ValueSpec, imports map[string]string) Declaration {
-	if len(vs.Values) > 0 {
-		v := getExprValue(pkg, vs.Values[0])
-		if v == "" {
-			fmt.Println("failed to determine value for " + pkg.getText(vs.Pos(), vs.End()))
+func (c *content) addGenDecl(pkg Pkg, gd *ast.GenDecl, imports map[string]string) {
+	var typ ast.Expr
+	var values []ast.Expr
+	for i, s := range gd.Specs {
+		vs := s.(*ast.ValueSpec)
+		if gd.Tok == token.CONST && vs.Type == nil && len(vs.Values) == 0 {
+			// "const ( A Kind = iota; B; C )"
+			vs = &ast.ValueSpec{Doc: vs.Doc, Names: vs.Names, Type: typ, Values: values, Comment: vs.Comment}
+		}
+		typ, values = vs.Type, vs.Values
+		for j, name := range vs.Names {
+			decl := NewDeclaration(pkg, vs, j, imports)
+			switch gd.Tok {
+			case token.CONST:
+				c.Consts[name.Name] = decl
+				if j < len(vs.Values) {
+					c.constExprs[name.Name] = constExpr{expr: vs.Values[j], ident: name, imports: imports, iota: int64(i)}
+				}
+			case token.VAR:
+				c.Vars[name.Name] = decl
+				if decl.Type.text != "" {
+					break
+				}
+				if call, ok := vs.Values[0].(*ast.CallExpr); ok && len(vs.Values) == 1 {
+					// "var a, b = f()" assigns f's results to a and b
+					c.varCalls[name.Name] = varCall{call: call, imports: imports, result: j}
+				} else if j < len(vs.Values) {
+					if call, ok := vs.Values[j].(*ast.CallExpr); ok {
+						c.varCalls[name.Name] = varCall{call: call, imports: imports}
+					}
+				}
+			default:
+				fmt.Fprintf(os.Stderr, "unexpected declaration kind %v\n", gd.Tok)
+			}
 		}
 	}
-	decl := NewDeclaration(pkg, vs, imports)
-	// TODO handle multiple names like "var a, b = 42"
-	switch tok {
-	case token.CONST:
-		c.Consts[vs.Names[0].Name] = decl
-	case token.VAR:
-		c.Vars[vs.Names[0].Name] = decl
-	default:
-		fmt.Printf("unexpected declaration kind %v\n", vs.Names[0].Obj.Kind)
-	}
-	return decl
 }
 
 func getExprValue(pkg Pkg, expr ast.Expr) string {
@@ -108,107 +140,21 @@ func getExprValue(pkg Pkg, expr ast.Expr) string {
 		// const FooConst = -1
 		return pkg.getText(x.Pos(), x.End())
 	default:
-		fmt.Printf("unhandled expression value type %T\n", expr)
+		fmt.Fprintf(os.Stderr, "unhandled expression value type %T\n", expr)
 		txt := pkg.getText(expr.Pos(), expr.End())
 		return txt
 	}
 }
 
-func includesType(s []string, t string) bool {
+func includesType(s []typeExpr, t typeExpr) bool {
 	for _, j := range s {
-		if j == t {
+		if j.text == t.text {
 			return true
 		}
 	}
 	return false
 }
 
-func (c *content) parseSimpleType(tokenList *[]Token) {
-	keys := make([]string, 0, len(c.SimpleTypes))
-	for name := range c.SimpleTypes {
-		if unicode.IsUpper(rune(name[0])) {
-			keys = append(keys, name)
-		}
-	}
-	sort.Strings(keys)
-	for _, name := range keys {
-		t := c.SimpleTypes[name]
-		*tokenList = append(*tokenList, t.MakeTokens()...)
-		c.searchForMethods(t.Name(), tokenList)
-		if consts := c.filterDeclarations(t.Name(), c.Consts); len(consts) > 0 {
-			c.parseDeclarations(consts, "const", tokenList)
-		}
-		if vars := c.filterDeclarations(t.Name(), c.Vars); len(vars) > 0 {
-			c.parseDeclarations(vars, "var", tokenList)
-		}
-	}
-}
-
-func (c *content) parseConst(tokenList *[]Token) {
-	c.parseDeclarations(c.Consts, "const", tokenList)
-}
-
-func (c *content) parseVar(tokenList *[]Token) {
-	c.parseDeclarations(c.Vars, "var", tokenList)
-}
-
-func (c *content) parseDeclarations(decls map[string]Declaration, kind string, tokenList *[]Token) {
-	if len(decls) < 1 {
-		return
-	}
-	// create keys slice in order to later sort consts by their types
-	keys := []string{}
-	// create types slice in order to be able to separate consts by the type they represent
-	types := []string{}
-	for name, d := range decls {
-		if r := rune(name[0]); r != '_' && unicode.IsUpper(r) {
-			keys = append(keys, name)
-			if !includesType(types, d.Type) {
-				types = append(types, d.Type)
-			}
-		}
-	}
-	sort.Strings(keys)
-	sort.Strings(types)
-	// finalKeys will order const keys by their type
-	finalKeys := []string{}
-	for _, t := range types {
-		for _, k := range keys {
-			if t == decls[k].Type {
-				finalKeys = append(finalKeys, k)
-			}
-		}
-	}
-	for _, t := range types {
-		// this token parsing is performed so that const declarations of different types are declared
-		// in their own const block to make them easier to click on
-		makeToken(nil, nil, kind, TokenTypeKeyword, tokenList)
-		makeToken(nil, nil, " ", TokenTypeWhitespace, tokenList)
-		makeToken(nil, nil, "(", TokenTypePunctuation, tokenList)
-		makeToken(nil, nil, "", 1, tokenList)
-		for _, v := range finalKeys {
-			if decls[v].Type == t {
-				makeToken(nil, nil, "\t", TokenTypeWhitespace, tokenList)
-				*tokenList = append(*tokenList, decls[v].MakeTokens()...)
-			}
-		}
-		makeToken(nil, nil, ")", TokenTypePunctuation, tokenList)
-		makeToken(nil, nil, "", 1, tokenList)
-		makeToken(nil, nil, "", TokenTypeNewline, tokenList)
-		c.searchForPossibleValuesMethod(t, tokenList)
-	}
-}
-
-func (c *content) searchForPossibleValuesMethod(t string, tokenList *[]Token) {
-	for i, f := range c.Funcs {
-		if f.Name() == fmt.Sprintf("Possible%sValues", removeNavigatorString(t)) {
-			*tokenList = append(*tokenList, f.MakeTokens()...)
-			delete(c.Funcs, i)
-			return
-		}
-	}
-}
-
 // addFunc adds the specified function declaration to the exports list
 // The imports map stores the key value pair for package imports which will be used to identify types.
 func (c *content) addFunc(pkg Pkg, f *ast.FuncDecl, imports map[string]string) Func {
@@ -233,26 +179,87 @@ func (c *content) addSimpleType(pkg Pkg, name, packageName string, underlyingTyp
 	return t
 }
 
-// addInterface adds the specified interface type to the exports list.
-// The imports map stores the key value pair for package imports which will be used to identify types.
-func (c *content) addInterface(source Pkg, name, packageName string, i *ast.InterfaceType, imports map[string]string) Interface {
-	in := NewInterface(source, name, packageName, i, imports)
-	c.Interfaces[name] = in
-	return in
+// addSimpleTypeExpr adds the simple type declared by the specified type spec to the exports list
+func (c *content) addSimpleTypeExpr(pkg Pkg, packageName string, ts *ast.TypeSpec, imports map[string]string) SimpleType {
+	names, constraints := newTypeParams(pkg, ts.TypeParams, imports)
+	t := NewSimpleType(ts.Name.Name, packageName, pkg.stripTypeParams(pkg.translateExpr(ts.Type, imports), names))
+	t.typeParamNames, t.typeParamConstraints = names, constraints
+	c.SimpleTypes[ts.Name.Name] = t
+	return t
 }
 
-// adds the specified struct type to the exports list.
-func (c *content) parseInterface(tokenList *[]Token) {
-	keys := []string{}
-	for name := range c.Interfaces {
-		if unicode.IsUpper(rune(name[0])) {
-			keys = append(keys, name)
+// setDoc sets the doc comment of the named type
+func (c *content) setDoc(name string, doc []string) {
+	if in, ok := c.Interfaces[name]; ok {
+		in.doc = doc
+		c.Interfaces[name] = in
+	} else if st, ok := c.SimpleTypes[name]; ok {
+		st.doc = doc
+		c.SimpleTypes[name] = st
+	} else if s, ok := c.Structs[name]; ok {
+		s.doc = doc
+		c.Structs[name] = s
+	}
+}
+
+// setPromoted sets the members promoted to the named struct or interface
+func (c *content) setPromoted(name string, members []promotedMember) {
+	if len(members) > 0 {
+		c.promoted[name] = members
+	}
+}
+
+// setPosition sets the location of the named type
+func (c *content) setPosition(name string, pos token.Position) {
+	if in, ok := c.Interfaces[name]; ok {
+		in.pos = pos
+		c.Interfaces[name] = in
+	} else if st, ok := c.SimpleTypes[name]; ok {
+		st.pos = pos
+		c.SimpleTypes[name] = st
+	} else if s, ok := c.Structs[name]; ok {
+		s.pos = pos
+		c.Structs[name] = s
+	}
+}
+
+// positions maps the IDs of exported declarations, including interface methods
+// and struct fields, to their locations.
+func (c content) positions() map[string]token.Position {
+	positions := map[string]token.Position{}
+	for _, d := range c.Consts {
+		positions[d.ID()] = d.Position()
+	}
+	for _, f := range c.Funcs {
+		positions[f.ID()] = f.Position()
+	}
+	for _, i := range c.Interfaces {
+		positions[i.ID()] = i.Position()
+		for _, m := range i.methods {
+			positions[m.ID()] = m.Position()
 		}
 	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		*tokenList = append(*tokenList, c.Interfaces[k].MakeTokens()...)
+	for _, t := range c.SimpleTypes {
+		positions[t.ID()] = t.Position()
+	}
+	for _, s := range c.Structs {
+		positions[s.ID()] = s.Position()
+		for name, pos := range s.fieldPositions {
+			positions[name+"-"+s.ID()] = pos
+		}
 	}
+	for _, v := range c.Vars {
+		positions[v.ID()] = v.Position()
+	}
+	return positions
+}
+
+// addInterface adds the specified interface type to the exports list.
+// The imports map stores the key value pair for package imports which will be used to identify types.
+func (c *content) addInterface(source Pkg, name, packageName string, ts *ast.TypeSpec, imports map[string]string) Interface {
+	in := NewInterface(source, name, packageName, ts, imports)
+	c.Interfaces[name] = in
+	return in
 }
 
 // addStruct adds the specified struct type to the exports list.
@@ -263,73 +270,39 @@ func (c *content) addStruct(source Pkg, name, packageName string, ts *ast.TypeSp
 	return s
 }
 
-// adds the specified struct type to the exports list.
-func (c *content) parseStruct(tokenList *[]Token) {
-	keys := make([]string, 0, len(c.Structs))
-	for name := range c.Structs {
-		if unicode.IsUpper(rune(name[0])) {
-			keys = append(keys, name)
-		}
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		*tokenList = append(*tokenList, c.Structs[k].MakeTokens()...)
-		typeName := k
-		ctors := c.searchForCtors(typeName)
-		if len(ctors) > 0 {
-			keys := make([]string, 0, len(ctors))
-			for k := range ctors {
-				keys = append(keys, k)
-			}
-			sort.Strings(keys)
-			for _, k := range keys {
-				*tokenList = append(*tokenList, ctors[k].MakeTokens()...)
-			}
-		}
-		c.searchForMethods(typeName, tokenList)
-		if consts := c.filterDeclarations(typeName, c.Consts); len(consts) > 0 {
-			c.parseDeclarations(consts, "const", tokenList)
-		}
-		if vars := c.filterDeclarations(typeName, c.Vars); len(vars) > 0 {
-			c.parseDeclarations(vars, "var", tokenList)
-		}
-	}
-}
-
-// searchForCtors searches through exported Funcs for constructors of a type,
+// findCtors searches through exported Funcs for constructors of a type,
 // given that type's name. A Func is a constructor of type T when:
 // 1. it has no receiver
 // 2. its name begins with "New"
 // 3. it returns T or *T
-func (c *content) searchForCtors(s string) map[string]Func {
+func (c *content) findCtors(s string) map[string]Func {
 	ctors := map[string]Func{}
 	for key, f := range c.Funcs {
 		if f.ReceiverType != "" || !strings.HasPrefix(f.Name(), "New") {
 			continue
 		}
-		for _, rt := range f.Returns {
+		for _, r := range f.Returns {
+			rt := r.text
 			if before, _, found := strings.Cut(rt, "["); found {
 				// ignore type parameters when matching
 				rt = before
 			}
-			rt = removeNavigatorString(rt)
 			if rt == s || rt == "*"+s {
 				ctors[key] = f
-				delete(c.Funcs, key)
 			}
 		}
 	}
 	return ctors
 }
 
-// filterDeclarations returns a subset of decls containing only items matching the specified type, deleting them from the given map
-func (c *content) filterDeclarations(typ string, decls map[string]Declaration) map[string]Declaration {
+// filterDeclarations returns a subset of decls containing only items matching the specified type
+func filterDeclarations(typ string, decls map[string]Declaration) map[string]Declaration {
 	results := map[string]Declaration{}
 	for name, decl := range decls {
-		t := removeNavigatorString(decl.Type)
+		// vars of pointer or instantiated generic types belong with the type
+		t, _, _ := strings.Cut(strings.TrimPrefix(decl.Type.text, "*"), "[")
 		if typ == t {
 			results[name] = decl
-			delete(decls, name)
 		}
 	}
 	return results
@@ -348,7 +321,6 @@ func (c *content) findMethods(s string) map[string]Func {
 			// ignore type parameters when matching receivers to types
 			n = before
 		}
-		n = removeNavigatorString(n)
 		if s == n || "*"+s == n {
 			methods[key] = fn
 		}
@@ -356,21 +328,6 @@ func (c *content) findMethods(s string) map[string]Func {
 	return methods
 }
 
-// searchForMethods takes the name of the receiver and looks for Funcs that are methods on that receiver.
-func (c *content) searchForMethods(s string, tokenList *[]Token) {
-	methods := c.findMethods(s)
-	methodNames := []string{}
-	for key := range methods {
-		methodNames = append(methodNames, key)
-	}
-	sort.Strings(methodNames)
-	for _, name := range methodNames {
-		fn := methods[name]
-		*tokenList = append(*tokenList, fn.MakeTokens()...)
-		delete(c.Funcs, name)
-	}
-}
-
 // receiverRegex captures a receiver's type and optional name
 var receiverRegex = regexp.MustCompile(`\((\w*)?(?: ?(\*?\w.*)\))?`)
 
@@ -404,110 +361,11 @@ func isExampleOrTest(s string) bool {
 	return strings.Contains(s, "Example") || strings.Contains(s, "Test")
 }
 
-func (c *content) parseFunc(tokenList *[]Token) {
-	keys := make([]string, 0, len(c.Funcs))
-	for key, fn := range c.Funcs {
-		name := fn.Name()
-		if !(isOnUnexportedMember(key) || isExampleOrTest(name) || unicode.IsLower(rune(name[0]))) {
-			keys = append(keys, key)
-		}
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		*tokenList = append(*tokenList, c.Funcs[k].MakeTokens()...)
-	}
-}
-
-// generateNavChildItems will loop through all the consts, interfaces, structs and global functions
-// to create the navigation items that will be displayed in the API view.
-// For consts, a navigation item will be by const type.
-// For interfaces, a navigation item will point to the interface definition.
-// For structs, a navigation item will only point to the struct definition and not methods or functions related to the struct.
-// For funcs, global funcs that are not constructors for any structs will have a direct navigation item.
-func (c *content) generateNavChildItems() []Navigation {
-	items := []Navigation{}
-	for _, cst := range c.Consts {
-		if cst.Exported() {
-			items = append(items, Navigation{
-				Text:         cst.Name(),
-				NavigationId: cst.ID(),
-				ChildItems:   []Navigation{},
-				Tags: &map[string]string{
-					"TypeKind": "enum",
-				},
-			})
-		}
-	}
-	for _, f := range c.Funcs {
-		if f.Exported() {
-			items = append(items, Navigation{
-				Text:         f.Name(),
-				NavigationId: f.ID(),
-				ChildItems:   []Navigation{},
-				Tags: &map[string]string{
-					"TypeKind": "delegate",
-				},
-			})
-		}
-	}
-	for _, i := range c.Interfaces {
-		if i.Exported() {
-			items = append(items, Navigation{
-				Text:         i.Name(),
-				NavigationId: i.ID(),
-				ChildItems:   []Navigation{},
-				Tags: &map[string]string{
-					"TypeKind": "interface",
-				},
-			})
-		}
-	}
-	for _, n := range c.SimpleTypes {
-		if n.Exported() {
-			items = append(items, Navigation{
-				Text:         n.Name(),
-				NavigationId: n.ID(),
-				ChildItems:   []Navigation{},
-				Tags: &map[string]string{
-					"TypeKind": "struct",
-				},
-			})
-		}
-	}
-	for _, s := range c.Structs {
-		if s.Exported() {
-			items = append(items, Navigation{
-				Text:         s.Name(),
-				NavigationId: s.ID(),
-				ChildItems:   []Navigation{},
-				Tags: &map[string]string{
-					"TypeKind": "class",
-				},
-			})
-		}
-	}
-	for _, v := range c.Vars {
-		if v.Exported() {
-			items = append(items, Navigation{
-				Text:         v.Name(),
-				NavigationId: v.ID(),
-				ChildItems:   []Navigation{},
-				Tags: &map[string]string{
-					"TypeKind": "unknown",
-				},
-			})
-		}
-	}
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].Text < items[j].Text
-	})
-	return items
-}
-
-// removeNavigatorString help to remove any navigator ("<xxx>") in types for easy comparison
-func removeNavigatorString(str string) string {
-	if i := strings.Index(str, ">"); i > 0 {
-		str = str[i+1:]
+// navTags returns the navigation tags for an item of the given kind having the given doc comment
+func navTags(typeKind string, doc []string) *map[string]string {
+	tags := map[string]string{"TypeKind": typeKind}
+	if _, ok := deprecationNotice(doc); ok {
+		tags["Deprecated"] = "true"
 	}
-	return str
+	return &tags
 }
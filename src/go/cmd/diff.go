@@ -0,0 +1,479 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <baseModuleDir> <moduleDir>",
+	Short: "Reports changes to a module's API",
+	Long: `diff compares the exported API of two versions of a module and reports
+removed, added and changed exports. Each change is classified as breaking or additive.`,
+	Args:          cobra.ExactArgs(2),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := diffModules(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		switch diffFormat {
+		case "text":
+			d.writeText(os.Stdout)
+		case "json":
+			b, err := json.MarshalIndent(d, "", " ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+		default:
+			return fmt.Errorf("unknown format %q", diffFormat)
+		}
+		return nil
+	},
+}
+
+var diffFormat string
+
+func init() {
+	diffCmd.Flags().StringVar(&diffFormat, "format", "text", `output format, "text" or "json"`)
+	rootCmd.AddCommand(diffCmd)
+}
+
+// ChangeKind describes how an export changed
+type ChangeKind string
+
+const (
+	ChangeKindAdded      ChangeKind = "added"
+	ChangeKindChanged    ChangeKind = "changed"
+	ChangeKindDeprecated ChangeKind = "deprecated"
+	ChangeKindRemoved    ChangeKind = "removed"
+)
+
+// Change is a difference between two versions of a module's API
+type Change struct {
+	// Breaking is true when the change can break code depending on the base version
+	Breaking bool       `json:"Breaking"`
+	Kind     ChangeKind `json:"Kind"`
+	// Package is the changed package's name relative to its module, for example "azcore/runtime"
+	Package string `json:"Package"`
+	// Text describes the change e.g. "New field `Bar` in struct `BazOptions`"
+	Text string `json:"Text"`
+}
+
+// APIDiff is the set of changes between two versions of a module's API
+type APIDiff struct {
+	Changes []Change `json:"Changes"`
+}
+
+// Breaking returns the breaking changes
+func (d APIDiff) Breaking() []Change {
+	return d.filter(func(c Change) bool { return c.Breaking })
+}
+
+// Additive returns the changes that add to the API without breaking it
+func (d APIDiff) Additive() []Change {
+	return d.filter(func(c Change) bool { return !c.Breaking && c.Kind != ChangeKindDeprecated })
+}
+
+// Other returns the changes that neither add to nor break the API, such as deprecations
+func (d APIDiff) Other() []Change {
+	return d.filter(func(c Change) bool { return !c.Breaking && c.Kind == ChangeKindDeprecated })
+}
+
+func (d APIDiff) filter(include func(Change) bool) []Change {
+	result := []Change{}
+	for _, c := range d.Changes {
+		if include(c) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func (d APIDiff) writeText(w io.Writer) {
+	for _, section := range []struct {
+		heading string
+		changes []Change
+	}{
+		{"Breaking changes", d.Breaking()},
+		{"Additive changes", d.Additive()},
+		{"Other changes", d.Other()},
+	} {
+		if len(section.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s:\n", section.heading)
+		for _, c := range section.changes {
+			fmt.Fprintf(w, "  %s: %s\n", c.Package, c.Text)
+		}
+	}
+	if len(d.Changes) == 0 {
+		fmt.Fprintln(w, "No API changes")
+	}
+}
+
+// diffModules compares the APIs of two versions of a module
+func diffModules(baseDir, dir string) (APIDiff, error) {
+	base, err := NewModule(baseDir, Options{})
+	if err != nil {
+		return APIDiff{}, err
+	}
+	m, err := NewModule(dir, Options{})
+	if err != nil {
+		return APIDiff{}, err
+	}
+	return diffAPIs(base, m), nil
+}
+
+// diffAPIs compares the exported content of the public packages of two modules
+func diffAPIs(base, m *Module) APIDiff {
+	d := &differ{}
+	basePkgs, pkgs := publicPackages(base), publicPackages(m)
+	for name, bp := range basePkgs {
+		p, ok := pkgs[name]
+		if !ok {
+			d.add(name, ChangeKindRemoved, true, "Package `%s` has been removed", name)
+			continue
+		}
+		d.pkg = name
+		d.diffContent(bp.c, p.c)
+	}
+	for name := range pkgs {
+		if _, ok := basePkgs[name]; !ok {
+			d.add(name, ChangeKindAdded, false, "New package `%s`", name)
+		}
+	}
+	sort.Slice(d.changes, func(i, j int) bool {
+		a, b := d.changes[i], d.changes[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		return a.Text < b.Text
+	})
+	return APIDiff{Changes: d.changes}
+}
+
+// publicPackages returns the module's non-internal packages keyed by their names relative to the module
+func publicPackages(m *Module) map[string]*Pkg {
+	pkgs := map[string]*Pkg{}
+	for _, p := range m.packages {
+		if strings.Contains(p.relName, "/internal") {
+			continue
+		}
+		pkgs[p.relName] = p
+	}
+	return pkgs
+}
+
+// differ accumulates the changes found while comparing the content of packages
+type differ struct {
+	changes []Change
+	// pkg is the name of the package being compared
+	pkg string
+}
+
+func (d *differ) add(pkg string, kind ChangeKind, breaking bool, format string, args ...any) {
+	d.changes = append(d.changes, Change{Breaking: breaking, Kind: kind, Package: pkg, Text: fmt.Sprintf(format, args...)})
+}
+
+func (d *differ) added(format string, args ...any) {
+	d.add(d.pkg, ChangeKindAdded, false, format, args...)
+}
+
+func (d *differ) removed(format string, args ...any) {
+	d.add(d.pkg, ChangeKindRemoved, true, format, args...)
+}
+
+func (d *differ) changed(breaking bool, format string, args ...any) {
+	d.add(d.pkg, ChangeKindChanged, breaking, format, args...)
+}
+
+// deprecated adds a change when doc has a deprecation notice and baseDoc doesn't
+func (d *differ) deprecated(baseDoc, doc []string, format string, args ...any) {
+	_, wasDeprecated := deprecationNotice(baseDoc)
+	if _, ok := deprecationNotice(doc); ok && !wasDeprecated {
+		d.add(d.pkg, ChangeKindDeprecated, false, format+" has been deprecated", args...)
+	}
+}
+
+func (d *differ) diffContent(base, c content) {
+	baseKinds, kinds := typeKinds(base), typeKinds(c)
+	for name, kind := range baseKinds {
+		if newKind, ok := kinds[name]; !ok {
+			d.removed("%s `%s` has been removed", kindTitle(kind), name)
+		} else if newKind != kind {
+			d.changed(true, "Type of `%s` has been changed from %s to %s", name, kind, newKind)
+		} else {
+			d.deprecated(typeDoc(base, name), typeDoc(c, name), "%s `%s`", kindTitle(kind), name)
+		}
+	}
+	for name, kind := range kinds {
+		if _, ok := baseKinds[name]; !ok {
+			d.added("New %s `%s`", kind, name)
+		}
+	}
+
+	for name, bs := range base.Structs {
+		if s, ok := c.Structs[name]; ok && bs.Exported() {
+			d.diffStruct(bs, s)
+		}
+	}
+	for name, bi := range base.Interfaces {
+		if i, ok := c.Interfaces[name]; ok && bi.Exported() {
+			d.diffInterface(bi, i)
+		}
+	}
+	for name, bt := range base.SimpleTypes {
+		if t, ok := c.SimpleTypes[name]; ok && bt.Exported() {
+			if a, b := bt.underlyingType.text, t.underlyingType.text; a != b {
+				d.changed(true, "Underlying type of `%s` has been changed from `%s` to `%s`", name, a, b)
+			}
+		}
+	}
+	d.diffFuncs(base.Funcs, c.Funcs)
+	d.diffDeclarations("const", base.Consts, c.Consts)
+	d.diffDeclarations("variable", base.Vars, c.Vars)
+}
+
+// typeDoc returns the doc comment of the named type
+func typeDoc(c content, name string) []string {
+	if s, ok := c.Structs[name]; ok {
+		return s.doc
+	}
+	if i, ok := c.Interfaces[name]; ok {
+		return i.doc
+	}
+	return c.SimpleTypes[name].doc
+}
+
+// typeKinds maps the names of exported types to their kinds
+func typeKinds(c content) map[string]string {
+	kinds := map[string]string{}
+	for name, s := range c.Structs {
+		if s.Exported() {
+			kinds[name] = "struct"
+		}
+	}
+	for name, i := range c.Interfaces {
+		if i.Exported() {
+			kinds[name] = "interface"
+		}
+	}
+	for name, t := range c.SimpleTypes {
+		if t.Exported() {
+			kinds[name] = "type"
+		}
+	}
+	return kinds
+}
+
+func (d *differ) diffStruct(base, s Struct) {
+	for _, f := range base.AnonymousFields {
+		if exportedFieldRgx.MatchString(f.text) && !includesType(s.AnonymousFields, f) {
+			d.removed("Field `%s` of struct `%s` has been removed", f, s.name)
+		}
+	}
+	for _, f := range s.AnonymousFields {
+		if exportedFieldRgx.MatchString(f.text) && !includesType(base.AnonymousFields, f) {
+			d.added("New anonymous field `%s` in struct `%s`", f, s.name)
+		}
+	}
+	for name, bt := range base.fields {
+		if !unicode.IsUpper(rune(name[0])) {
+			continue
+		}
+		if t, ok := s.fields[name]; !ok {
+			d.removed("Field `%s` of struct `%s` has been removed", name, s.name)
+		} else if a, b := bt.text, t.text; a != b {
+			d.changed(true, "Type of `%s.%s` has been changed from `%s` to `%s`", s.name, name, a, b)
+		} else {
+			d.deprecated(base.fieldDocs[name], s.fieldDocs[name], "Field `%s` of struct `%s`", name, s.name)
+		}
+	}
+	for name := range s.fields {
+		if _, ok := base.fields[name]; !ok && unicode.IsUpper(rune(name[0])) {
+			d.added("New field `%s` in struct `%s`", name, s.name)
+		}
+	}
+}
+
+func (d *differ) diffInterface(base, i Interface) {
+	// adding a method to an interface breaks its implementations, unless applications can't implement it
+	addBreaks := !base.Sealed
+	for _, e := range base.embeddedInterfaces {
+		if !includesType(i.embeddedInterfaces, e) {
+			d.removed("Interface `%s` no longer embeds `%s`", i.name, e)
+		}
+	}
+	for _, e := range i.embeddedInterfaces {
+		if !includesType(base.embeddedInterfaces, e) {
+			d.add(d.pkg, ChangeKindAdded, addBreaks, "Interface `%s` now embeds `%s`", i.name, e)
+		}
+	}
+	for name, bm := range base.methods {
+		if !bm.Exported() {
+			continue
+		}
+		if m, ok := i.methods[name]; !ok {
+			d.removed("Method `%s` of interface `%s` has been removed", name, i.name)
+		} else {
+			d.diffSignatures(i.name+"."+name, bm, m)
+			d.deprecated(bm.doc, m.doc, "Method `%s` of interface `%s`", name, i.name)
+		}
+	}
+	for name, m := range i.methods {
+		if _, ok := base.methods[name]; !ok && m.Exported() {
+			d.add(d.pkg, ChangeKindAdded, addBreaks, "New method `%s` in interface `%s`", name, i.name)
+		}
+	}
+}
+
+func (d *differ) diffFuncs(base, funcs map[string]Func) {
+	baseFuncs, newFuncs := funcsByName(base), funcsByName(funcs)
+	for name, bf := range baseFuncs {
+		if f, ok := newFuncs[name]; !ok {
+			d.removed("Function `%s` has been removed", name)
+		} else {
+			d.diffSignatures(name, bf, f)
+			d.deprecated(bf.doc, f.doc, "Function `%s`", name)
+		}
+	}
+	for name, f := range newFuncs {
+		if _, ok := baseFuncs[name]; !ok {
+			d.added("New function `%s%s`", name, signature(f))
+		}
+	}
+}
+
+// funcsByName maps exported funcs by names which, unlike the keys of content.Funcs, don't depend on receiver
+// names. For example, "func (c *Client) Do()" has the name "*Client.Do".
+func funcsByName(funcs map[string]Func) map[string]Func {
+	result := map[string]Func{}
+	for _, f := range funcs {
+		if f.Exported() && !isExampleOrTest(f.Name()) {
+			result[funcName(f)] = f
+		}
+	}
+	return result
+}
+
+// funcName returns a func's name qualified by its receiver type, if any
+func funcName(f Func) string {
+	if f.ReceiverType == "" {
+		return f.Name()
+	}
+	recv := f.ReceiverType
+	if before, _, found := strings.Cut(recv, "["); found {
+		recv = before
+	}
+	return recv + "." + f.Name()
+}
+
+func (d *differ) diffSignatures(name string, base, f Func) {
+	if a, b := typeParamList(base.typeParamNames, base.typeParamConstraints), typeParamList(f.typeParamNames, f.typeParamConstraints); a != b {
+		d.changed(true, "Type parameters of function `%s` have been changed from `%s` to `%s`", name, a, b)
+	}
+	if a, b := paramList(base), paramList(f); a != b {
+		d.changed(true, "Function `%s` parameter(s) have been changed from `%s` to `%s`", name, a, b)
+	}
+	if a, b := returnList(base), returnList(f); a != b {
+		d.changed(true, "Function `%s` return value(s) have been changed from `%s` to `%s`", name, a, b)
+	}
+}
+
+// signature returns the func's signature without parameter names e.g. "(string, int) error"
+func signature(f Func) string {
+	sig := typeParamList(f.typeParamNames, f.typeParamConstraints) + paramList(f)
+	if r := returnList(f); r != "" {
+		sig += " " + r
+	}
+	return sig
+}
+
+// typeParamList returns a type parameter list like "[K comparable, V any]", or "" when there are no type parameters
+func typeParamList(names []string, constraints []typeExpr) string {
+	if len(names) == 0 {
+		return ""
+	}
+	params := make([]string, len(names))
+	for i, n := range names {
+		params[i] = n + " " + constraints[i].text
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+func paramList(f Func) string {
+	return "(" + strings.Join(typeTexts(f.paramTypes), ", ") + ")"
+}
+
+func returnList(f Func) string {
+	returns := typeTexts(f.Returns)
+	if len(returns) == 1 {
+		return returns[0]
+	}
+	if len(returns) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(returns, ", ") + ")"
+}
+
+func (d *differ) diffDeclarations(kind string, base, decls map[string]Declaration) {
+	for name, bd := range base {
+		if !bd.Exported() {
+			continue
+		}
+		dd, ok := decls[name]
+		if !ok {
+			if enum := enumType(kind, bd); enum != "" {
+				d.removed("`%s` from enum `%s` has been removed", name, enum)
+			} else {
+				d.removed("%s `%s` has been removed", kindTitle(kind), name)
+			}
+			continue
+		}
+		if a, b := bd.Type.text, dd.Type.text; a != b && a != skip && b != skip {
+			d.changed(true, "Type of %s `%s` has been changed from `%s` to `%s`", kind, name, a, b)
+		}
+		if kind == "const" && bd.value != dd.value {
+			d.changed(true, "Value of const `%s` has been changed from `%s` to `%s`", name, bd.value, dd.value)
+		}
+		d.deprecated(bd.doc, dd.doc, "%s `%s`", kindTitle(kind), name)
+	}
+	for name, dd := range decls {
+		if _, ok := base[name]; !ok && dd.Exported() {
+			if enum := enumType(kind, dd); enum != "" {
+				d.added("New value `%s` added to enum type `%s`", name, enum)
+			} else {
+				d.added("New %s `%s`", kind, name)
+			}
+		}
+	}
+}
+
+// enumType returns the name of the type of a const declared in the same package i.e. the type of
+// an enum value, or "" when the declaration isn't such a const
+func enumType(kind string, decl Declaration) string {
+	if kind != "const" || decl.Type.navigator() == "" {
+		return ""
+	}
+	return decl.Type.text
+}
+
+func kindTitle(kind string) string {
+	return strings.ToUpper(kind[:1]) + kind[1:]
+}
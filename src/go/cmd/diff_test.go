@@ -0,0 +1,83 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffModules(t *testing.T) {
+	d, err := diffModules(filepath.Clean("testdata/test_diff/old/widgets"), filepath.Clean("testdata/test_diff/new/widgets"))
+	require.NoError(t, err)
+
+	texts := func(changes []Change) []string {
+		result := []string{}
+		for _, c := range changes {
+			require.Equal(t, "widgets", c.Package)
+			result = append(result, c.Text)
+		}
+		return result
+	}
+	require.Equal(t, []string{
+		"Function `*Client.Delete` has been removed",
+		"Struct `Removed` has been removed",
+		"Function `*Client.Get` parameter(s) have been changed from `(context.Context, string)` to `(context.Context, string, int)`",
+		"Type of `BazOptions.Count` has been changed from `*string` to `*int32`",
+		"Underlying type of `Mode` has been changed from `int` to `string`",
+		"Value of const `ColorBlue` has been changed from `\"blue\"` to `\"navy\"`",
+		"New method `Perimeter` in interface `Shape`",
+	}, texts(d.Breaking()))
+	require.Equal(t, []string{
+		"New field `Bar` in struct `BazOptions`",
+		"New function `NewFooClient() *Client`",
+		"New value `ColorGreen` added to enum type `Color`",
+	}, texts(d.Additive()))
+	require.Equal(t, []string{"Const `MaxSize` has been deprecated"}, texts(d.Other()))
+
+	buf := bytes.Buffer{}
+	d.writeText(&buf)
+	require.Contains(t, buf.String(), "Breaking changes:\n  widgets: Function `*Client.Delete` has been removed\n")
+}
+
+func TestDiffModulesNoChanges(t *testing.T) {
+	dir := filepath.Clean("testdata/test_diff/new/widgets")
+	d, err := diffModules(dir, dir)
+	require.NoError(t, err)
+	require.Empty(t, d.Changes)
+}
+
+func TestDiffJSONOutput(t *testing.T) {
+	// indexing this module writes a message about an unhandled node type, which mustn't corrupt the JSON on stdout
+	dir := filepath.Clean("testdata/test_unhandled_types")
+	stdoutR, stdoutW, err := os.Pipe()
+	require.NoError(t, err)
+	stderrR, stderrW, err := os.Pipe()
+	require.NoError(t, err)
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdoutW, stderrW
+	diffFormat = "json"
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		diffFormat = "text"
+	})
+	err = diffCmd.RunE(diffCmd, []string{dir, dir})
+	os.Stdout, os.Stderr = stdout, stderr
+	require.NoError(t, err)
+	require.NoError(t, stdoutW.Close())
+	require.NoError(t, stderrW.Close())
+
+	out, err := io.ReadAll(stdoutR)
+	require.NoError(t, err)
+	require.True(t, json.Valid(out), string(out))
+	messages, err := io.ReadAll(stderrR)
+	require.NoError(t, err)
+	require.Contains(t, string(messages), "unhandled node type *ast.ChanType")
+}
@@ -0,0 +1,574 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// This file contains rules checking the Azure SDK for Go's API guidelines. Except for the ContextFirstErrorLast
+// rule, which applies to any module, they check only packages importing azcore, because other modules don't
+// follow the guidelines.
+
+const azcoreImportPath = "github.com/Azure/azure-sdk-for-go/sdk/azcore"
+
+// importsAzcore returns true when any of the package's files imports azcore or one of its packages
+func (p *Pkg) importsAzcore() bool {
+	if strings.HasPrefix(p.modulePath, azcoreImportPath) {
+		return true
+	}
+	for _, f := range p.p.Files {
+		for _, imp := range f.Imports {
+			if path := strings.Trim(imp.Path.Value, `"`); path == azcoreImportPath || strings.HasPrefix(path, azcoreImportPath+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// clients returns the package's exported clients, structs whose names end with "Client", sorted by name.
+// It returns nil for packages not importing azcore.
+func (p *Pkg) clients() []Struct {
+	if !p.importsAzcore() {
+		return nil
+	}
+	clients := []Struct{}
+	for _, name := range sortedKeys(p.c.Structs) {
+		if s := p.c.Structs[name]; s.Exported() && strings.HasSuffix(name, "Client") {
+			clients = append(clients, s)
+		}
+	}
+	return clients
+}
+
+// clientCtorVariants are the suffixes of the names of a client's constructors other than New<Client>, which
+// construct the client with other kinds of credentials
+var clientCtorVariants = []string{"FromConnectionString", "WithKeyCredential", "WithNoCredential", "WithSharedKeyCredential"}
+
+// clientOptionsEmbeds are the embedded fields of a <Client>Options struct providing azcore's client options
+var clientOptionsEmbeds = []string{"azcore.ClientOptions", "policy.ClientOptions"}
+
+// checkClientConstructors returns diagnostics for exported clients not following the constructor conventions.
+// A client is a struct whose name ends with "Client". Clients have a New<Client> constructor taking an endpoint,
+// a credential and *<Client>Options, whose struct embeds azcore.ClientOptions, and returning (*<Client>, error).
+// Clients of ARM modules instead take *arm.ClientOptions. Clients created by a documented factory method of
+// another client, such as "func (c *Client) NewWidgetClient() *WidgetClient", don't need a constructor.
+func checkClientConstructors(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, s := range p.clients() {
+		name := s.Name()
+		report := func(format string, args ...any) {
+			diagnostics = append(diagnostics, Diagnostic{
+				Level:    DiagnosticLevelWarning,
+				TargetID: s.ID(),
+				Text:     clientConstructor + fmt.Sprintf(format, args...),
+			})
+		}
+		primary := "New" + name
+		options := "*" + name + "Options"
+		ctors := p.c.findCtors(name)
+		// armOptions is true when the client's constructors take *arm.ClientOptions, making <Client>Options unnecessary
+		hasPrimary, armOptions := false, false
+		for _, key := range sortedKeys(ctors) {
+			ctor := ctors[key]
+			params := typeTexts(ctor.paramTypes)
+			sig := "(" + strings.Join(params, ", ") + ")"
+			switch {
+			case ctor.Name() == primary:
+				hasPrimary = true
+				switch sig {
+				case "(string, azcore.TokenCredential, " + options + ")":
+				case "(string, azcore.TokenCredential, *arm.ClientOptions)", "(azcore.TokenCredential, *arm.ClientOptions)":
+					armOptions = true
+				default:
+					report("%s parameters should be (endpoint string, credential azcore.TokenCredential, options %s), not %s", primary, options, sig)
+				}
+			case isClientCtorVariant(ctor.Name(), primary):
+				if len(params) == 0 || params[len(params)-1] != options && params[len(params)-1] != "*arm.ClientOptions" {
+					report("%s should take options %s as its last parameter", ctor.Name(), options)
+				}
+			default:
+				report("%s isn't %s or an approved variant of it (%s%s)", ctor.Name(), primary, primary, strings.Join(clientCtorVariants, ", "+primary))
+			}
+			if returns := strings.Join(typeTexts(ctor.Returns), ", "); returns != "*"+name+", error" {
+				report("%s should return (*%s, error), not (%s)", ctor.Name(), name, returns)
+			}
+		}
+		if !hasPrimary {
+			factories, documented := clientFactories(p, name)
+			switch {
+			case len(factories) == 0:
+				report("has no %s constructor or factory method", primary)
+			case !documented:
+				report("has no %s constructor, and factory method %s has no doc comment", primary, factories[0])
+			}
+		}
+		if len(ctors) == 0 || armOptions {
+			continue
+		}
+		if o, ok := p.c.Structs[name+"Options"]; !ok {
+			report("%sOptions doesn't exist", name)
+		} else if !embedsAny(o, clientOptionsEmbeds) {
+			report("%sOptions doesn't embed azcore.ClientOptions", name)
+		}
+	}
+	return diagnostics
+}
+
+// isClientCtorVariant returns true when name is the name of an approved variant of the primary constructor
+func isClientCtorVariant(name, primary string) bool {
+	for _, v := range clientCtorVariants {
+		if name == primary+v {
+			return true
+		}
+	}
+	return false
+}
+
+// clientFactories returns the names of methods of other types in the package returning the named client,
+// sorted, and whether any of them has a doc comment
+func clientFactories(p *Pkg, client string) ([]string, bool) {
+	names := []string{}
+	documented := false
+	for _, key := range sortedKeys(p.c.Funcs) {
+		f := p.c.Funcs[key]
+		if f.ReceiverType == "" || strings.TrimPrefix(f.ReceiverType, "*") == client || !f.Exported() || len(f.Returns) == 0 {
+			continue
+		}
+		if rt := f.Returns[0].text; rt != "*"+client && rt != client {
+			continue
+		}
+		names = append(names, f.Name())
+		documented = documented || len(f.doc) > 0
+	}
+	return names, documented
+}
+
+// embedsAny returns true when the struct embeds any of the given types
+func embedsAny(s Struct, types []string) bool {
+	for _, f := range s.AnonymousFields {
+		for _, t := range types {
+			if f.text == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pagerMethodRgx matches the names of methods returning pagers, capturing the name of their operation e.g. "List" for "NewListPager"
+var pagerMethodRgx = regexp.MustCompile(`^New(\w+)Pager$`)
+
+// clientOperation is an operation of a client, a method taking a context or returning a pager
+type clientOperation struct {
+	method Func
+	// options and response are the names of the types of the operation's options and response
+	options, response string
+	// plain is true when the operation returns (response, error) rather than a pager or poller
+	plain bool
+}
+
+// clientOperations returns the operations of the named client, sorted by method name. The options of an
+// operation are named <Client><Method>Options, except that pager methods such as NewListPager have
+// <Client>ListOptions. The response is named <Client><Method>Response, without the "Begin" prefix of
+// long-running operations' methods and the "New" and "Pager" of pager methods.
+func (p *Pkg) clientOperations(client string) []clientOperation {
+	ops := []clientOperation{}
+	methods := p.c.findMethods(client)
+	for _, key := range sortedKeys(methods) {
+		f := methods[key]
+		params := typeTexts(f.paramTypes)
+		op := clientOperation{method: f}
+		if m := pagerMethodRgx.FindStringSubmatch(f.Name()); m != nil {
+			op.options, op.response = client+m[1]+"Options", client+m[1]+"Response"
+		} else if len(params) > 0 && params[0] == "context.Context" {
+			op.options, op.response = client+f.Name()+"Options", client+strings.TrimPrefix(f.Name(), "Begin")+"Response"
+			op.plain = !strings.HasPrefix(f.Name(), "Begin")
+		} else {
+			continue
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// definesType returns true when the package defines the named type
+func (p *Pkg) definesType(name string) bool {
+	_, isStruct := p.c.Structs[name]
+	_, isSimpleType := p.c.SimpleTypes[name]
+	return isStruct || isSimpleType
+}
+
+// checkClientMethods returns diagnostics for client operations whose options or response don't follow the
+// conventions. The last parameter of an operation is "options *<Client><Method>Options" and, unless the operation
+// returns a pager or poller, its results are (<Client><Method>Response, error). Both types must be defined in the package.
+func checkClientMethods(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, s := range p.clients() {
+		for _, op := range p.clientOperations(s.Name()) {
+			report := func(format string, args ...any) {
+				diagnostics = append(diagnostics, Diagnostic{
+					Level:    DiagnosticLevelWarning,
+					TargetID: op.method.ID(),
+					Text:     clientMethod + fmt.Sprintf(format, args...),
+				})
+			}
+			name := op.method.Name()
+			params := typeTexts(op.method.paramTypes)
+			if last := len(params) - 1; last < 0 || params[last] != "*"+op.options || op.method.paramNames[last] != "options" {
+				report("%s should take options *%s as its last parameter", name, op.options)
+			}
+			if op.plain {
+				if results := strings.Join(typeTexts(op.method.Returns), ", "); results != op.response+", error" {
+					report("%s should return (%s, error), not (%s)", name, op.response, results)
+				}
+			}
+			for _, t := range []string{op.options, op.response} {
+				if !p.definesType(t) {
+					report("%s isn't defined", t)
+				}
+			}
+		}
+	}
+	return diagnostics
+}
+
+// checkOrphanedOptionsResponses returns a diagnostic for each exported type whose name is a client's name followed by
+// an operation name and "Options" or "Response", which no func's signature uses. <Client>Options is the client's options.
+func checkOrphanedOptionsResponses(_ *Module, p *Pkg) []Diagnostic {
+	clients := p.clients()
+	if len(clients) == 0 {
+		return nil
+	}
+	used := map[string]bool{}
+	for _, f := range p.c.Funcs {
+		for _, t := range append(slices.Clone(f.paramTypes), f.Returns...) {
+			for _, tok := range t.tokens {
+				if tok.Kind == TokenTypeTypeName {
+					used[tok.Value] = true
+				}
+			}
+		}
+	}
+	diagnostics := []Diagnostic{}
+	for _, name := range append(sortedKeys(p.c.Structs), sortedKeys(p.c.SimpleTypes)...) {
+		if used[name] || !unicode.IsUpper(rune(name[0])) || !strings.HasSuffix(name, "Options") && !strings.HasSuffix(name, "Response") {
+			continue
+		}
+		for _, c := range clients {
+			if strings.HasPrefix(name, c.Name()) && name != c.Name()+"Options" && name != c.Name()+"Response" {
+				id := p.c.Structs[name].ID()
+				if t, ok := p.c.SimpleTypes[name]; ok {
+					id = t.ID()
+				}
+				diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelWarning, TargetID: id, Text: orphanedOptionsResponse + name})
+				break
+			}
+		}
+	}
+	return diagnostics
+}
+
+// file returns the file containing pos, which is one of the package's files or, for definitions hoisted from
+// aliased types, a file of the package defining them. It returns nil when no such file is found.
+func (p *Pkg) file(pos token.Position) *ast.File {
+	if f, ok := p.p.Files[pos.Filename]; ok {
+		return f
+	}
+	for _, s := range p.aliasSources {
+		if f := s.file(pos); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// importName returns the name by which the file containing pos refers to the package having the given import
+// path, or "" when the file doesn't import it
+func (p *Pkg) importName(pos token.Position, importPath string) string {
+	f := p.file(pos)
+	if f == nil {
+		return ""
+	}
+	for _, imp := range f.Imports {
+		if strings.Trim(imp.Path.Value, `"`) != importPath {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return path.Base(importPath)
+	}
+	return ""
+}
+
+// contextType returns the name by which the file containing pos refers to context.Context, or "" when the file
+// doesn't import package context
+func (p *Pkg) contextType(pos token.Position) string {
+	switch name := p.importName(pos, "context"); name {
+	case "":
+		return ""
+	case ".":
+		return "Context"
+	default:
+		return name + ".Context"
+	}
+}
+
+// checkContextAndErrors returns diagnostics for exported funcs returning an error other than last, or taking a
+// context.Context other than first, for clients' methods performing I/O without taking a context first, and for
+// exported structs embedding context.Context or having an exported field of that type. context is matched under
+// whatever name each file imports it. A client's methods returning an error perform I/O, except factory methods of
+// other clients, and pager methods whose pagers take a context instead.
+func checkContextAndErrors(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	report := func(id, format string, args ...any) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Level:    DiagnosticLevelWarning,
+			TargetID: id,
+			Text:     contextFirstErrorLast + fmt.Sprintf(format, args...),
+		})
+	}
+	// performsIO holds the keys of clients' methods performing I/O
+	performsIO := map[string]bool{}
+	for _, c := range p.clients() {
+		for key, f := range p.c.findMethods(c.Name()) {
+			returns := typeTexts(f.Returns)
+			if !slices.Contains(returns, "error") || pagerMethodRgx.MatchString(f.Name()) {
+				continue
+			}
+			if r := strings.TrimPrefix(returns[0], "*"); strings.HasPrefix(f.Name(), "New") && strings.HasSuffix(r, "Client") {
+				continue
+			}
+			performsIO[key] = true
+		}
+	}
+	for _, key := range sortedKeys(p.c.Funcs) {
+		f := p.c.Funcs[key]
+		if !f.Exported() || isOnUnexportedMember(key) {
+			continue
+		}
+		params := typeTexts(f.paramTypes)
+		i := -1
+		if ctx := p.contextType(f.Position()); ctx != "" {
+			i = slices.Index(params, ctx)
+		}
+		if i > 0 || i < 0 && performsIO[key] {
+			report(f.ID(), "%s should take ctx context.Context as its first parameter", f.Name())
+		}
+		if returns := typeTexts(f.Returns); slices.Contains(returns[:max(len(returns)-1, 0)], "error") {
+			report(f.ID(), "%s should return error last", f.Name())
+		}
+	}
+	for _, name := range sortedKeys(p.c.Structs) {
+		s := p.c.Structs[name]
+		if !s.Exported() {
+			continue
+		}
+		ctx := p.contextType(s.Position())
+		if ctx == "" {
+			continue
+		}
+		for _, field := range sortedKeys(s.fields) {
+			// unexported fields aren't part of the API, and their IDs don't identify any review line
+			if !unicode.IsUpper(rune(field[0])) {
+				continue
+			}
+			if s.fields[field].text == ctx {
+				report(field+"-"+s.ID(), "field %s shouldn't be a context.Context; pass contexts to methods instead", field)
+			}
+		}
+		if slices.Contains(typeTexts(s.AnonymousFields), ctx) {
+			report(s.ID(), "%s shouldn't embed context.Context; pass contexts to methods instead", name)
+		}
+	}
+	return diagnostics
+}
+
+// checkPagersPollers returns diagnostics for methods whose names and pager or poller results don't match. A New<Op>Pager
+// method returns *runtime.Pager[<Receiver><Op>Response], a Begin<Op> method returns
+// (*runtime.Poller[<Receiver><Op>Response], error), and methods returning a pager or poller are named so. runtime is
+// azcore's runtime package, under whatever name the method's file imports it, including a dot import.
+func checkPagersPollers(_ *Module, p *Pkg) []Diagnostic {
+	if !p.importsAzcore() {
+		return nil
+	}
+	diagnostics := []Diagnostic{}
+	for _, key := range sortedKeys(p.c.Funcs) {
+		f := p.c.Funcs[key]
+		if f.ReceiverType == "" || !f.Exported() || isOnUnexportedMember(key) {
+			continue
+		}
+		report := func(format string, args ...any) {
+			diagnostics = append(diagnostics, Diagnostic{
+				Level:    DiagnosticLevelWarning,
+				TargetID: f.ID(),
+				Text:     pagerPoller + fmt.Sprintf(format, args...),
+			})
+		}
+		receiver, _, _ := strings.Cut(strings.TrimPrefix(f.ReceiverType, "*"), "[")
+		// rewrite the results to refer to azcore's runtime package as "runtime", regardless of its import name.
+		// A dot import leaves pagers and pollers unqualified, which is unambiguous because the package can't
+		// then declare types of the same names.
+		returns := typeTexts(f.Returns)
+		if rt := p.importName(f.Position(), azcoreImportPath+"/runtime"); rt != "" && rt != "runtime" {
+			qualifier := rt + "."
+			if rt == "." {
+				qualifier = ""
+			}
+			for i, r := range returns {
+				for _, t := range []string{"Pager[", "Poller["} {
+					if strings.HasPrefix(r, "*"+qualifier+t) {
+						returns[i] = "*runtime." + strings.TrimPrefix(r, "*"+qualifier)
+					}
+				}
+			}
+		}
+		results := strings.Join(returns, ", ")
+		name := f.Name()
+		if m := pagerMethodRgx.FindStringSubmatch(name); m != nil {
+			if want := "*runtime.Pager[" + receiver + m[1] + "Response]"; results != want {
+				report("%s should return %s, not (%s)", name, want, results)
+			}
+			continue
+		}
+		// "Begin" must start a word, so methods such as Beginning aren't long-running operations
+		if op, ok := strings.CutPrefix(name, "Begin"); ok && strings.IndexFunc(op, unicode.IsUpper) == 0 {
+			if want := "*runtime.Poller[" + receiver + op + "Response], error"; results != want {
+				report("%s should return (%s), not (%s)", name, want, results)
+			}
+			continue
+		}
+		for _, r := range returns {
+			switch {
+			case strings.HasPrefix(r, "*runtime.Pager["):
+				report("%s returns a pager, so its name should be New<Operation>Pager", name)
+			case strings.HasPrefix(r, "*runtime.Poller["):
+				report("%s returns a poller, so its name should be Begin<Operation>", name)
+			}
+		}
+	}
+	return diagnostics
+}
+
+// possibleValues returns the names of the consts returned by the named func's body, when its body returns a single
+// composite literal of identifiers such as "[]Kind{KindA, KindB}", and false when the body can't be analyzed
+func (p *Pkg) possibleValues(pos token.Position, name string) ([]string, bool) {
+	f, ok := p.p.Files[pos.Filename]
+	if !ok {
+		return nil, false
+	}
+	var body *ast.BlockStmt
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && fd.Name.Name == name {
+			body = fd.Body
+			break
+		}
+	}
+	if body == nil || len(body.List) != 1 {
+		return nil, false
+	}
+	ret, ok := body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil, false
+	}
+	lit, ok := ret.Results[0].(*ast.CompositeLit)
+	if !ok {
+		return nil, false
+	}
+	values := []string{}
+	for _, elt := range lit.Elts {
+		ident, ok := elt.(*ast.Ident)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, ident.Name)
+	}
+	return values, true
+}
+
+// constType returns the name of the named const's type. Consts converting their values such as
+// `const KindA = Kind("a")` have no declared type, so their type is the conversion's.
+func (p *Pkg) constType(name string, d Declaration) string {
+	if d.Type.text != "" {
+		return d.Type.text
+	}
+	if call, ok := p.c.constExprs[name].expr.(*ast.CallExpr); ok && len(call.Args) == 1 {
+		if ident, ok := call.Fun.(*ast.Ident); ok {
+			return ident.Name
+		}
+	}
+	return ""
+}
+
+// checkEnums returns diagnostics for exported enums, string types having consts, whose Possible<Type>Values func
+// is missing, doesn't have the signature "func() []<Type>", or doesn't return all the enum's consts, and for consts
+// of an enum having the same value. It also reports types having a Possible<Type>Values func but no consts.
+func checkEnums(_ *Module, p *Pkg) []Diagnostic {
+	if !p.importsAzcore() {
+		return nil
+	}
+	consts := map[string][]Declaration{}
+	for _, name := range sortedKeys(p.c.Consts) {
+		if d := p.c.Consts[name]; d.Exported() {
+			t := p.constType(name, d)
+			consts[t] = append(consts[t], d)
+		}
+	}
+	diagnostics := []Diagnostic{}
+	report := func(id, format string, args ...any) {
+		diagnostics = append(diagnostics, Diagnostic{
+			Level:    DiagnosticLevelWarning,
+			TargetID: id,
+			Text:     enumValues + fmt.Sprintf(format, args...),
+		})
+	}
+	for _, name := range sortedKeys(p.c.SimpleTypes) {
+		t := p.c.SimpleTypes[name]
+		if !t.Exported() || t.underlyingType.text != "string" {
+			continue
+		}
+		possible := "Possible" + name + "Values"
+		f, hasFunc := p.c.Funcs[possible]
+		values := consts[name]
+		if len(values) == 0 {
+			if hasFunc {
+				report(t.ID(), "%s has %s but no consts", name, possible)
+			}
+			continue
+		}
+		if !hasFunc {
+			report(t.ID(), "%s has no %s func", name, possible)
+		} else if sig := "(" + strings.Join(typeTexts(f.paramTypes), ", ") + ") (" + strings.Join(typeTexts(f.Returns), ", ") + ")"; sig != "() ([]"+name+")" {
+			report(f.ID(), "%s should be func() []%s, not func%s", possible, name, sig)
+		} else if returned, ok := p.possibleValues(f.Position(), possible); ok {
+			for _, d := range values {
+				if !slices.Contains(returned, d.Name()) {
+					report(d.ID(), "%s doesn't return %s", possible, d.Name())
+				}
+			}
+		}
+		// first maps values to the first const having them
+		first := map[string]string{}
+		for _, d := range values {
+			if d.value == skip {
+				continue
+			}
+			if other, ok := first[d.value]; ok {
+				report(d.ID(), "%s has the same value as %s", d.Name(), other)
+			} else {
+				first[d.value] = d.Name()
+			}
+		}
+	}
+	return diagnostics
+}
@@ -0,0 +1,157 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// htmlStyle styles HTML reviews. Token classes are the render classes of the tree token format.
+const htmlStyle = `body { margin: 0; display: flex; font-family: sans-serif; }
+nav { position: sticky; top: 0; height: 100vh; overflow: auto; min-width: 16em; padding: 0 1em; border-right: 1px solid #ddd; background: #f8f8f8; font-size: 0.9em; }
+nav ul { list-style: none; padding-left: 1em; margin: 0; }
+nav a { color: #333; text-decoration: none; }
+main { flex: 1; padding: 0 1em; overflow: auto; }
+pre { font-family: Consolas, monospace; font-size: 0.9em; }
+a { color: inherit; }
+.line:target, [id]:target { background: #fff3b0; }
+.keyword { color: #0000ff; }
+.tname { color: #2b91af; }
+.mname { color: #74531f; }
+.sliteral { color: #a31515; }
+.literal { color: #098658; }
+.comment { color: #008000; }
+.deprecated { text-decoration: line-through; }
+.diagnostic { display: block; margin: 0.2em 0; padding: 0.2em 0.5em; border-left: 4px solid; font-family: sans-serif; white-space: normal; }
+.diagnostic.info { border-color: #2b91af; background: #eef6fa; }
+.diagnostic.warning { border-color: #d7a000; background: #fdf6e3; }
+.diagnostic.error { border-color: #c00; background: #fbeaea; }
+`
+
+// writeHTML renders a review as a self-contained HTML page having a navigation sidebar. Diagnostics
+// follow the line defining their target; those whose target isn't rendered precede the review.
+func writeHTML(w io.Writer, review PackageReview) error {
+	diagnostics := map[string][]Diagnostic{}
+	for _, d := range review.Diagnostics {
+		diagnostics[d.TargetID] = append(diagnostics[d.TargetID], d)
+	}
+	defined := map[string]bool{}
+	for _, t := range review.Tokens {
+		if t.DefinitionID != nil {
+			defined[*t.DefinitionID] = true
+		}
+	}
+
+	sb := strings.Builder{}
+	title := html.EscapeString(review.Name)
+	fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", title, htmlStyle)
+	sb.WriteString("<nav>\n")
+	writeHTMLNavigation(&sb, review.Navigation)
+	sb.WriteString("</nav>\n")
+	fmt.Fprintf(&sb, "<main>\n<h1>%s</h1>\n", title)
+	for _, d := range review.Diagnostics {
+		if !defined[d.TargetID] {
+			writeHTMLDiagnostic(&sb, d)
+		}
+	}
+	sb.WriteString("<pre>")
+	// targets holds the IDs defined on the current line, whose diagnostics follow the line
+	targets := []string{}
+	// anchored holds the IDs having an element, because an HTML ID must be unique
+	anchored := map[string]bool{}
+	// deprecated counts the open deprecated ranges, which are closed at the end of each line and reopened on the next
+	deprecated := 0
+	lineStart := true
+	for _, t := range review.Tokens {
+		if lineStart {
+			sb.WriteString(`<span class="line">`)
+			sb.WriteString(strings.Repeat(`<span class="deprecated">`, deprecated))
+			lineStart = false
+		}
+		switch t.Kind {
+		case TokenTypeNewline:
+			sb.WriteString(strings.Repeat("</span>", deprecated))
+			sb.WriteString("</span>\n")
+			for _, id := range targets {
+				for _, d := range diagnostics[id] {
+					writeHTMLDiagnostic(&sb, d)
+				}
+			}
+			targets = targets[:0]
+			lineStart = true
+			continue
+		case TokenTypeDeprecatedRangeStart:
+			deprecated++
+			sb.WriteString(`<span class="deprecated">`)
+			continue
+		case TokenTypeDeprecatedRangeEnd:
+			deprecated--
+			sb.WriteString("</span>")
+			continue
+		case TokenTypeLineIDMarker:
+			if t.DefinitionID != nil && !anchored[*t.DefinitionID] {
+				anchored[*t.DefinitionID] = true
+				fmt.Fprintf(&sb, `<span id="%s"></span>`, html.EscapeString(*t.DefinitionID))
+				targets = append(targets, *t.DefinitionID)
+			}
+			continue
+		case TokenTypeWhitespace:
+			sb.WriteString(html.EscapeString(t.Value))
+			continue
+		}
+		value := html.EscapeString(t.Value)
+		if t.NavigateToID != nil && defined[*t.NavigateToID] {
+			value = fmt.Sprintf(`<a href="%s">%s</a>`, htmlFragment(*t.NavigateToID), value)
+		}
+		attrs := ""
+		if class, ok := renderClasses[t.Kind]; ok {
+			attrs = fmt.Sprintf(` class="%s"`, class)
+		}
+		if t.DefinitionID != nil && !anchored[*t.DefinitionID] {
+			anchored[*t.DefinitionID] = true
+			attrs += fmt.Sprintf(` id="%s"`, html.EscapeString(*t.DefinitionID))
+			targets = append(targets, *t.DefinitionID)
+		}
+		fmt.Fprintf(&sb, "<span%s>%s</span>", attrs, value)
+	}
+	if !lineStart {
+		sb.WriteString(strings.Repeat("</span>", deprecated))
+		sb.WriteString("</span>\n")
+	}
+	sb.WriteString("</pre>\n</main>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeHTMLNavigation writes navigation items as nested lists of links
+func writeHTMLNavigation(sb *strings.Builder, items []Navigation) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString("<ul>\n")
+	for _, n := range items {
+		fmt.Fprintf(sb, `<li><a href="%s">%s</a>`, htmlFragment(n.NavigationId), html.EscapeString(n.Text))
+		writeHTMLNavigation(sb, n.ChildItems)
+		sb.WriteString("</li>\n")
+	}
+	sb.WriteString("</ul>\n")
+}
+
+// htmlFragment returns an escaped link to the element having the given ID.
+// Definition IDs can contain spaces e.g. "pkg-(c *Client) Get".
+func htmlFragment(id string) string {
+	return html.EscapeString((&url.URL{Fragment: id}).String())
+}
+
+func writeHTMLDiagnostic(sb *strings.Builder, d Diagnostic) {
+	text := html.EscapeString(d.Text)
+	if d.HelpLinkURI != "" {
+		text += fmt.Sprintf(` <a href="%s">(help)</a>`, html.EscapeString(d.HelpLinkURI))
+	}
+	fmt.Fprintf(sb, "<span class=\"diagnostic %s\">%s</span>", levelNames[d.Level], text)
+}
@@ -0,0 +1,48 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTML(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_deprecated"), Options{})
+	require.NoError(t, err)
+	review.Diagnostics = append(review.Diagnostics, Diagnostic{Level: DiagnosticLevelError, TargetID: "unrendered", Text: "a <module> diagnostic"})
+	sb := strings.Builder{}
+	require.NoError(t, writeHTML(&sb, review))
+	page := sb.String()
+
+	// deprecated ranges spanning lines are closed and reopened on each line
+	require.Equal(t, strings.Count(page, "<span"), strings.Count(page, "</span>"))
+	// sidebar
+	require.Contains(t, page, `<li><a href="#test_deprecated.Widget">Widget</a></li>`)
+	// definitions are anchors
+	require.Contains(t, page, `<span class="tname" id="test_deprecated.Gadget">Gadget</span>`)
+	// diagnostics follow the line defining their target
+	require.Contains(t, page, `<span class="tname" id="test_deprecated-OldFunc">OldFunc</span><span class="punc">(</span><span class="punc">)</span></span></span>
+<span class="diagnostic warning">Deprecation notice doesn&#39;t suggest a replacement: this paragraph continues. <a href="https://github.com/Azure/azure-sdk-tools/blob/main/src/go/README.md#deprecatednoreplacement">(help)</a></span>`)
+	require.Contains(t, page, `<span class="diagnostic error">a &lt;module&gt; diagnostic</span><pre>`)
+}
+
+func TestWriteHTMLLinks(t *testing.T) {
+	def, method := "pkg.Client", "pkg-(c *Client) Get"
+	review := PackageReview{Name: "pkg", Tokens: []Token{
+		{Kind: TokenTypeTypeName, Value: "Client", DefinitionID: &def},
+		{Kind: TokenTypeNewline, Value: "\n"},
+		{Kind: TokenTypeTypeName, Value: "Client", NavigateToID: &def},
+		{Kind: TokenTypeTypeName, Value: "Other", NavigateToID: &method},
+	}}
+	sb := strings.Builder{}
+	require.NoError(t, writeHTML(&sb, review))
+	// references link to definitions, those without a definition in the review aren't links
+	require.Contains(t, sb.String(), `<span class="line"><span class="tname"><a href="#pkg.Client">Client</a></span><span class="tname">Other</span></span>`)
+	// IDs containing spaces are escaped in links
+	require.Equal(t, "#pkg-(c%20*Client)%20Get", htmlFragment(method))
+}
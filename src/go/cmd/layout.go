@@ -0,0 +1,391 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"go/token"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Ordering is the order in which a review lists the declarations of each package
+type Ordering string
+
+const (
+	// OrderingKind lists interfaces, structs, other types, vars, consts and funcs, each alphabetically. It's the default.
+	OrderingKind Ordering = "kind"
+	// OrderingAlpha lists declarations alphabetically regardless of their kind
+	OrderingAlpha Ordering = "alpha"
+	// OrderingSource lists declarations in the order of their source files and positions
+	OrderingSource Ordering = "source"
+)
+
+// orderings are the valid values of Options.Order
+var orderings = []Ordering{"", OrderingKind, OrderingAlpha, OrderingSource}
+
+// declBlock is a const or var block of declarations having the same type
+type declBlock struct {
+	// kind is "const" or "var"
+	kind string
+	// typ is the text of the declarations' type
+	typ   string
+	decls []Declaration
+	// values is the "Possible<Type>Values" func listing the values of the block's type, if there is one
+	values *Func
+}
+
+// typeGroup is an exported type with its constructors, methods, and the consts and vars of the type
+type typeGroup struct {
+	def      TokenMaker
+	promoted []promotedMember
+	ctors    []Func
+	methods  []Func
+	blocks   []*declBlock
+}
+
+// layout groups a package's exports for rendering. Building it doesn't modify the package's content,
+// so exports are grouped the same way regardless of the order in which the groups are rendered.
+type layout struct {
+	interfaces []typeGroup
+	structs    []typeGroup
+	// simpleTypes have no constructors because only funcs returning structs are considered constructors
+	simpleTypes []typeGroup
+	// vars and consts are the blocks of declarations whose types aren't exported types of the package
+	vars   []*declBlock
+	consts []*declBlock
+	// funcs are the funcs not grouped with a type, keyed by signature
+	funcs map[string]Func
+}
+
+// newLayout groups the given content's exports
+func newLayout(c content) layout {
+	l := layout{funcs: map[string]Func{}}
+	// grouped holds the keys of funcs and the names of declarations grouped with a type
+	grouped := map[string]bool{}
+	group := func(def TokenMaker, ctors map[string]Func) typeGroup {
+		g := typeGroup{def: def, promoted: c.promoted[def.Name()]}
+		for _, key := range sortedKeys(ctors) {
+			if !grouped[key] {
+				g.ctors = append(g.ctors, ctors[key])
+				grouped[key] = true
+			}
+		}
+		methods := c.findMethods(def.Name())
+		for _, key := range sortedKeys(methods) {
+			g.methods = append(g.methods, methods[key])
+			grouped[key] = true
+		}
+		for _, kind := range []string{"const", "var"} {
+			decls := filterDeclarations(def.Name(), c.declarations(kind))
+			for name := range decls {
+				grouped[kind+" "+name] = true
+			}
+			g.blocks = append(g.blocks, newDeclBlocks(kind, decls)...)
+		}
+		return g
+	}
+	for _, name := range sortedKeys(c.Interfaces) {
+		if unicode.IsUpper(rune(name[0])) {
+			l.interfaces = append(l.interfaces, typeGroup{def: c.Interfaces[name], promoted: c.promoted[name]})
+		}
+	}
+	for _, name := range sortedKeys(c.Structs) {
+		if unicode.IsUpper(rune(name[0])) {
+			l.structs = append(l.structs, group(c.Structs[name], c.findCtors(name)))
+		}
+	}
+	for _, name := range sortedKeys(c.SimpleTypes) {
+		if unicode.IsUpper(rune(name[0])) {
+			l.simpleTypes = append(l.simpleTypes, group(c.SimpleTypes[name], nil))
+		}
+	}
+	for _, kind := range []string{"var", "const"} {
+		decls := map[string]Declaration{}
+		for name, d := range c.declarations(kind) {
+			if !grouped[kind+" "+name] {
+				decls[name] = d
+			}
+		}
+		if kind == "var" {
+			l.vars = newDeclBlocks(kind, decls)
+		} else {
+			l.consts = newDeclBlocks(kind, decls)
+		}
+	}
+	// each "Possible<Type>Values" func is listed after the first block of its type
+	funcKeys := sortedKeys(c.Funcs)
+	for _, b := range l.blocks() {
+		for _, key := range funcKeys {
+			if f := c.Funcs[key]; !grouped[key] && f.ReceiverType == "" && f.Name() == fmt.Sprintf("Possible%sValues", b.typ) {
+				b.values = &f
+				grouped[key] = true
+				break
+			}
+		}
+	}
+	for key, f := range c.Funcs {
+		if !grouped[key] {
+			l.funcs[key] = f
+		}
+	}
+	return l
+}
+
+// declarations returns the content's consts or vars
+func (c content) declarations(kind string) map[string]Declaration {
+	if kind == "const" {
+		return c.Consts
+	}
+	return c.Vars
+}
+
+// blocks returns all the layout's declaration blocks in the order OrderingKind renders them
+func (l layout) blocks() []*declBlock {
+	blocks := []*declBlock{}
+	for _, groups := range [][]typeGroup{l.structs, l.simpleTypes} {
+		for _, g := range groups {
+			blocks = append(blocks, g.blocks...)
+		}
+	}
+	blocks = append(blocks, l.vars...)
+	return append(blocks, l.consts...)
+}
+
+// newDeclBlocks separates exported declarations by type so that declarations of different types are
+// declared in their own block, to make them easier to click on. Blocks are sorted by type and
+// declarations by name.
+func newDeclBlocks(kind string, decls map[string]Declaration) []*declBlock {
+	blocks := map[string]*declBlock{}
+	for _, name := range sortedKeys(decls) {
+		if r := rune(name[0]); r == '_' || !unicode.IsUpper(r) {
+			continue
+		}
+		d := decls[name]
+		t := d.Type.text
+		if blocks[t] == nil {
+			blocks[t] = &declBlock{kind: kind, typ: t}
+		}
+		blocks[t].decls = append(blocks[t].decls, d)
+	}
+	sorted := []*declBlock{}
+	for _, t := range sortedKeys(blocks) {
+		sorted = append(sorted, blocks[t])
+	}
+	return sorted
+}
+
+// section is a top level element of a package's review: a type with the declarations grouped with it,
+// a const or var block, or a func
+type section struct {
+	// rank orders sections of different kinds for OrderingKind
+	rank int
+	// key orders sections of the same rank for OrderingKind, and breaks ties for other orderings
+	key  string
+	name string
+	pos  token.Position
+	// render appends the section's lines to a list, ordering any nested declarations the same way as sections
+	render func(order Ordering, lines *[]tokenLine)
+}
+
+// render appends lines for the layout's exports to the given list, in the given order
+func (l layout) render(order Ordering, lines *[]tokenLine) {
+	sections := []section{}
+	for rank, groups := range [][]typeGroup{l.interfaces, l.structs, l.simpleTypes} {
+		for _, g := range groups {
+			sections = append(sections, section{rank: rank, key: g.def.Name(), name: g.def.Name(), pos: g.def.Position(), render: g.render})
+		}
+	}
+	for i, blocks := range [][]*declBlock{l.vars, l.consts} {
+		for _, b := range blocks {
+			first := b.ordered(order)[0]
+			sections = append(sections, section{rank: 3 + i, key: b.typ, name: first.Name(), pos: first.Position(), render: b.render})
+		}
+	}
+	for key, f := range l.funcs {
+		name := f.Name()
+		if isOnUnexportedMember(key) || isExampleOrTest(name) || unicode.IsLower(rune(name[0])) {
+			continue
+		}
+		f := f
+		sections = append(sections, section{rank: 5, key: key, name: name, pos: f.Position(), render: func(_ Ordering, lines *[]tokenLine) {
+			*lines = append(*lines, f.MakeLines()...)
+		}})
+	}
+	slices.SortFunc(sections, func(a, b section) int {
+		c := 0
+		switch order {
+		case OrderingAlpha:
+			c = strings.Compare(a.name, b.name)
+		case OrderingSource:
+			c = comparePositions(a.pos, b.pos)
+		}
+		return firstNonZero(c, cmp.Compare(a.rank, b.rank), strings.Compare(a.key, b.key))
+	})
+	for _, s := range sections {
+		s.render(order, lines)
+	}
+}
+
+// render appends lines for the type and the declarations grouped with it
+func (g typeGroup) render(order Ordering, lines *[]tokenLine) {
+	*lines = append(*lines, g.def.MakeLines()...)
+	if len(g.promoted) > 0 {
+		*lines = append(*lines, makePromotedLines(g.def.ID(), g.promoted)...)
+	}
+	for _, funcs := range [][]Func{g.ctors, g.methods} {
+		funcs = slices.Clone(funcs)
+		if order != OrderingKind && order != "" {
+			sortMakers(funcs, order)
+		}
+		for _, f := range funcs {
+			*lines = append(*lines, f.MakeLines()...)
+		}
+	}
+	blocks := slices.Clone(g.blocks)
+	if order == OrderingSource {
+		slices.SortStableFunc(blocks, func(a, b *declBlock) int {
+			return comparePositions(a.ordered(order)[0].Position(), b.ordered(order)[0].Position())
+		})
+	}
+	for _, b := range blocks {
+		b.render(order, lines)
+	}
+}
+
+// ordered returns the block's declarations, which are sorted by name, in the given order
+func (b *declBlock) ordered(order Ordering) []Declaration {
+	if order != OrderingSource {
+		return b.decls
+	}
+	decls := slices.Clone(b.decls)
+	sortMakers(decls, order)
+	return decls
+}
+
+// render appends lines for the block and the func listing the possible values of its type, if any
+func (b *declBlock) render(order Ordering, lines *[]tokenLine) {
+	open := &[]Token{}
+	makeToken(nil, nil, b.kind, TokenTypeKeyword, open)
+	makeToken(nil, nil, " ", TokenTypeWhitespace, open)
+	makeToken(nil, nil, "(", TokenTypePunctuation, open)
+	decls := []tokenLine{}
+	for _, d := range b.ordered(order) {
+		decls = append(decls, d.MakeLines()...)
+	}
+	closing := &[]Token{}
+	makeToken(nil, nil, ")", TokenTypePunctuation, closing)
+	*lines = append(*lines, tokenLine{tokens: *open, children: decls}, tokenLine{tokens: *closing, closing: true}, blankLine)
+	if b.values != nil {
+		*lines = append(*lines, b.values.MakeLines()...)
+	}
+}
+
+// sortMakers sorts declarations alphabetically or by position, breaking ties by ID
+func sortMakers[T TokenMaker](s []T, order Ordering) {
+	slices.SortFunc(s, func(a, b T) int {
+		c := 0
+		if order == OrderingSource {
+			c = comparePositions(a.Position(), b.Position())
+		} else {
+			c = strings.Compare(a.Name(), b.Name())
+		}
+		return firstNonZero(c, strings.Compare(a.ID(), b.ID()))
+	})
+}
+
+// firstNonZero returns the first nonzero comparison result, or 0 when all are zero
+func firstNonZero(comparisons ...int) int {
+	for _, c := range comparisons {
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// comparePositions orders positions by file name, line and column
+func comparePositions(a, b token.Position) int {
+	return firstNonZero(strings.Compare(a.Filename, b.Filename), cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+}
+
+// sortedKeys returns a map's keys in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// generateNavChildItems creates the navigation items that will be displayed in the API view.
+// For consts and vars, there is a navigation item for each declaration not grouped with a type.
+// For interfaces, a navigation item will point to the interface definition.
+// For structs and other types, a navigation item will only point to the type definition and not methods or functions related to the type.
+// For funcs, global funcs that are not grouped with any type will have a direct navigation item.
+func (l layout) generateNavChildItems() []Navigation {
+	items := []Navigation{}
+	for _, b := range l.consts {
+		for _, cst := range b.decls {
+			items = append(items, Navigation{
+				Text:         cst.Name(),
+				NavigationId: cst.ID(),
+				ChildItems:   []Navigation{},
+				Tags:         navTags("enum", cst.doc),
+			})
+		}
+	}
+	for _, key := range sortedKeys(l.funcs) {
+		if f := l.funcs[key]; f.Exported() {
+			items = append(items, Navigation{
+				Text:         f.Name(),
+				NavigationId: f.ID(),
+				ChildItems:   []Navigation{},
+				Tags:         navTags("delegate", f.doc),
+			})
+		}
+	}
+	for _, g := range l.interfaces {
+		items = append(items, Navigation{
+			Text:         g.def.Name(),
+			NavigationId: g.def.ID(),
+			ChildItems:   []Navigation{},
+			Tags:         navTags("interface", g.def.(Interface).doc),
+		})
+	}
+	for _, g := range l.simpleTypes {
+		items = append(items, Navigation{
+			Text:         g.def.Name(),
+			NavigationId: g.def.ID(),
+			ChildItems:   []Navigation{},
+			Tags:         navTags("struct", g.def.(SimpleType).doc),
+		})
+	}
+	for _, g := range l.structs {
+		items = append(items, Navigation{
+			Text:         g.def.Name(),
+			NavigationId: g.def.ID(),
+			ChildItems:   []Navigation{},
+			Tags:         navTags("class", g.def.(Struct).doc),
+		})
+	}
+	for _, b := range l.vars {
+		for _, v := range b.decls {
+			items = append(items, Navigation{
+				Text:         v.Name(),
+				NavigationId: v.ID(),
+				ChildItems:   []Navigation{},
+				Tags:         navTags("unknown", v.doc),
+			})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Text < items[j].Text
+	})
+	return items
+}
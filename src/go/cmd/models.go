@@ -3,6 +3,8 @@
 
 package cmd
 
+import "go/token"
+
 // This file contains models comprising an APIView document
 
 type Diagnostic struct {
@@ -37,6 +39,11 @@ type PackageReview struct {
 	Tokens      []Token      `json:"Tokens,omitempty"`
 	Navigation  []Navigation `json:"Navigation,omitempty"`
 	PackageName string       `json:"PackageName,omitempty"`
+
+	// lines are the review's lines, from which Tokens and the tree token format's review lines are made
+	lines []tokenLine
+	// positions maps definition IDs to source locations. It isn't part of the APIView document.
+	positions map[string]token.Position
 }
 
 // Token ...
@@ -61,4 +68,56 @@ const (
 	TokenTypeStringLiteral TokenType = 8
 	TokenTypeLiteral       TokenType = 9
 	TokenTypeComment       TokenType = 10
+	// TokenTypeDeprecatedRangeStart and TokenTypeDeprecatedRangeEnd enclose the tokens of a deprecated API
+	TokenTypeDeprecatedRangeStart TokenType = 13
+	TokenTypeDeprecatedRangeEnd   TokenType = 14
+)
+
+// The types below model the hierarchical (tree token) APIView document, in which
+// structure is expressed by nesting review lines instead of newline and whitespace tokens.
+
+// CodeFile is an APIView document in the tree token format
+type CodeFile struct {
+	Diagnostics []Diagnostic `json:"Diagnostics,omitempty"`
+	Language    string       `json:"Language"`
+	Navigation  []Navigation `json:"Navigation,omitempty"`
+	PackageName string       `json:"PackageName"`
+	ReviewLines []ReviewLine `json:"ReviewLines"`
+}
+
+// ReviewLine is one line of a CodeFile. Children are rendered nested under the line.
+type ReviewLine struct {
+	Children []ReviewLine `json:"Children,omitempty"`
+	// IsContextEndLine marks a line closing the context of the line before it e.g. a struct's "}"
+	IsContextEndLine bool `json:"IsContextEndLine,omitempty"`
+	// LineID is the DefinitionID of the line's defining token, if any
+	LineID string `json:"LineId,omitempty"`
+	// RelatedToLine is the LineID of a line this one belongs to e.g. a doc comment's declaration
+	RelatedToLine string        `json:"RelatedToLine,omitempty"`
+	Tokens        []ReviewToken `json:"Tokens"`
+}
+
+// ReviewToken is a token of a ReviewLine
+type ReviewToken struct {
+	HasSuffixSpace bool            `json:"HasSuffixSpace"`
+	IsDeprecated   bool            `json:"IsDeprecated,omitempty"`
+	Kind           ReviewTokenKind `json:"Kind"`
+	NavigateToID   string          `json:"NavigateToId,omitempty"`
+	// NavigationDisplayName is set on tokens defining an item in the review's navigation
+	NavigationDisplayName string   `json:"NavigationDisplayName,omitempty"`
+	RenderClasses         []string `json:"RenderClasses,omitempty"`
+	Value                 string   `json:"Value"`
+}
+
+type ReviewTokenKind int
+
+const (
+	ReviewTokenKindText          ReviewTokenKind = 0
+	ReviewTokenKindPunctuation   ReviewTokenKind = 1
+	ReviewTokenKindKeyword       ReviewTokenKind = 2
+	ReviewTokenKindTypeName      ReviewTokenKind = 3
+	ReviewTokenKindMemberName    ReviewTokenKind = 4
+	ReviewTokenKindStringLiteral ReviewTokenKind = 5
+	ReviewTokenKindLiteral       ReviewTokenKind = 6
+	ReviewTokenKindComment       ReviewTokenKind = 7
 )
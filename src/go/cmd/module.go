@@ -7,6 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
 	"io/fs"
 	"os"
 	"path"
@@ -37,6 +40,16 @@ type Module struct {
 
 	// packages maps import paths to packages
 	packages map[string]*Pkg
+
+	// build selects the files of the module's packages, and of packages defining types it exports by alias
+	build buildConfig
+
+	// path is the module path declared in go.mod
+	path string
+
+	// skippedDirs maps the names of directories having no library package to descriptions of the packages
+	// skipped there, which the SkippedPackage rule reports on the packages of the closest parent directories
+	skippedDirs map[string][]string
 }
 
 var majorVerSuffix = regexp.MustCompile(`/v\d+$`)
@@ -56,8 +69,15 @@ func getPackageNameFromModPath(modPath string) string {
 	return modPath
 }
 
-// NewModule indexes an Azure SDK module's ASTs
-func NewModule(dir string) (*Module, error) {
+// baseModuleName returns the last element of a module path, ignoring any major version suffix.
+// For example, "github.com/Azure/azure-sdk-for-go/sdk/azcore/v2" returns "azcore".
+func baseModuleName(modPath string) string {
+	return filepath.Base(strings.TrimSuffix(versionReg.ReplaceAllString(modPath, "/"), "/"))
+}
+
+// NewModule indexes an Azure SDK module's ASTs. When opts.TypeCheck is set, it type checks the
+// module's packages so that identifiers in the review are resolved by go/types.
+func NewModule(dir string, opts Options) (*Module, error) {
 	mf, err := parseModFile(dir)
 	if err != nil {
 		return nil, err
@@ -70,8 +90,8 @@ func NewModule(dir string) (*Module, error) {
 	}
 
 	packageName := getPackageNameFromModPath(mf.Module.Mod.Path)
-	fmt.Printf("Package Name: %s\n", packageName)
-	m := &Module{Name: filepath.Base(dir), PackageName: packageName, packages: map[string]*Pkg{}}
+	fmt.Fprintf(os.Stderr, "Package Name: %s\n", packageName)
+	m := &Module{Name: filepath.Base(dir), PackageName: packageName, packages: map[string]*Pkg{}, build: newBuildConfig(opts), path: mf.Module.Mod.Path, skippedDirs: map[string][]string{}}
 
 	baseImportPath := path.Dir(mf.Module.Mod.Path) + "/"
 	if baseImportPath == "./" {
@@ -92,9 +112,12 @@ func NewModule(dir string) (*Module, error) {
 					return filepath.SkipDir
 				}
 			}
-			p, err := NewPkg(path, mf.Module.Mod.Path)
+			p, err := NewPkg(path, mf.Module.Mod.Path, m.build)
+			var skipped *skippedPackagesError
 			if err == nil {
 				m.packages[baseImportPath+p.Name()] = p
+			} else if errors.As(err, &skipped) {
+				m.skippedDirs[skipped.dir] = skipped.skipped
 			} else if !errors.Is(err, ErrNoPackages) {
 				return err
 			}
@@ -105,9 +128,18 @@ func NewModule(dir string) (*Module, error) {
 		return nil, err
 	}
 
+	if opts.TypeCheck {
+		imp := newModuleImporter(m)
+		for _, p := range m.packages {
+			// errors are import cycles, which the importing package's type checking reports
+			_, _ = p.typeCheck(imp)
+		}
+	}
+
 	for _, p := range m.packages {
 		p.Index()
 	}
+	m.resolveVarTypes()
 
 	// Add the definitions of types exported by alias to each package's content. For example,
 	// given "type TokenCredential = shared.TokenCredential" in package azcore, this will hoist
@@ -122,9 +154,58 @@ func NewModule(dir string) (*Module, error) {
 	for _, p := range m.packages {
 		recursiveResolveTypeAliases(m, p, externalPackages, sdkRoot, processedPackages)
 	}
+
+	config, err := loadRuleConfig(opts.Rules)
+	if err != nil {
+		return nil, err
+	}
+	m.runRules(config)
+	if opts.StructTags {
+		for _, p := range m.packages {
+			p.c.showStructTags()
+		}
+	}
+
+	if opts.Promoted {
+		m.resolvePromotedMembers()
+	}
 	return m, nil
 }
 
+// moduleImporter imports packages for type checking. It type checks the module's own packages as they're
+// imported, and imports other packages from source, so that type checking doesn't require compiled dependencies.
+// One importer serves all the module's packages, so that each dependency is type checked only once.
+type moduleImporter struct {
+	packages map[string]*Pkg
+	source   types.ImporterFrom
+}
+
+func newModuleImporter(m *Module) *moduleImporter {
+	mi := &moduleImporter{
+		packages: map[string]*Pkg{},
+		source:   importer.ForCompiler(token.NewFileSet(), "source", nil).(types.ImporterFrom),
+	}
+	for _, p := range m.packages {
+		mi.packages[p.importPath()] = p
+	}
+	return mi
+}
+
+func (mi *moduleImporter) Import(path string) (*types.Package, error) {
+	return mi.ImportFrom(path, ".", 0)
+}
+
+func (mi *moduleImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if p, ok := mi.packages[path]; ok {
+		return p.typeCheck(mi)
+	}
+	// the go command finds dependencies from the importing module's directory, which isn't necessarily the working directory
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return mi.source.ImportFrom(path, dir, mode)
+}
+
 func recursiveResolveTypeAliases(m *Module, p *Pkg, externalPackages map[string]*Pkg, sdkRoot string, processedPackages map[string]struct{}) {
 	if _, ok := processedPackages[p.relName]; ok {
 		// already processed this package
@@ -144,14 +225,14 @@ func recursiveResolveTypeAliases(m *Module, p *Pkg, externalPackages map[string]
 				// figure out a path to the package, index it
 				if _, after, found := strings.Cut(impPath, "azure-sdk-for-go/sdk/"); found {
 					p := filepath.Join(sdkRoot, strings.TrimSuffix(versionReg.ReplaceAllString(after, "/"), "/"))
-					pkg, err := NewPkg(p, "github.com/Azure/azure-sdk-for-go/sdk/"+after)
+					pkg, err := NewPkg(p, "github.com/Azure/azure-sdk-for-go/sdk/"+after, m.build)
 					if err == nil {
 						pkg.Index()
 						externalPackages[impPath] = pkg
 						source = pkg
 					} else {
 						// types from this module will appear in the review without their definitions
-						fmt.Printf("couldn't parse %s: %v\n", impPath, err)
+						fmt.Fprintf(os.Stderr, "couldn't parse %s: %v\n", impPath, err)
 					}
 				}
 			}
@@ -161,22 +242,28 @@ func recursiveResolveTypeAliases(m *Module, p *Pkg, externalPackages map[string]
 			recursiveResolveTypeAliases(m, source, externalPackages, sdkRoot, processedPackages)
 		}
 
-		level := DiagnosticLevelInfo
+		external := false
 		originalName := qn
 		if _, after, found := strings.Cut(qn, m.Name); found {
 			originalName = strings.TrimPrefix(after, "/")
 		} else {
 			// this type is defined in another module
-			level = DiagnosticLevelWarning
+			external = true
 		}
 
 		var t TokenMaker
+		var doc []string
+		// fieldTypes are the names of the types of the aliased struct's exported fields
+		var fieldTypes []string
 		if source == nil {
 			t = p.c.addSimpleType(*p, alias, p.Name(), originalName, nil)
 		} else if def, ok := recursiveFindTypeDef(typeName, source, m.packages); ok {
+			p.aliasSources[source.importPath()] = source
+			p.aliasSources[def.p.importPath()] = def.p
+			doc = docLines(def.n.Doc)
 			switch n := def.n.Type.(type) {
 			case *ast.InterfaceType:
-				t = p.c.addInterface(*def.p, alias, p.Name(), n, nil)
+				t = p.c.addInterface(*def.p, alias, p.Name(), def.n, nil)
 			case *ast.StructType:
 				t = p.c.addStruct(*def.p, alias, p.Name(), def.n, nil)
 				hoistMethodsForType(source, alias, p)
@@ -188,36 +275,26 @@ func recursiveResolveTypeAliases(m *Module, p *Pkg, externalPackages map[string]
 						continue
 					}
 
-					// ensure that our package exports this type
-					if _, ok := p.typeAliases[fieldTypeName]; ok {
-						// found an alias
-						continue
-					}
-
-					// no alias, add a diagnostic
-					p.diagnostics = append(p.diagnostics, Diagnostic{
-						Level:    DiagnosticLevelError,
-						TargetID: t.ID(),
-						Text:     missingAliasFor + fieldTypeName,
-					})
+					fieldTypes = append(fieldTypes, fieldTypeName)
 				}
 			case *ast.Ident:
 				t = p.c.addSimpleType(*p, alias, p.Name(), def.n.Type.(*ast.Ident).Name, nil)
 				hoistMethodsForType(source, alias, p)
 			default:
-				fmt.Printf("unexpected node type %T\n", def.n.Type)
+				fmt.Fprintf(os.Stderr, "unexpected node type %T\n", def.n.Type)
 				t = p.c.addSimpleType(*p, alias, p.Name(), originalName, nil)
 			}
 		} else {
-			fmt.Println("found no definition for " + qn)
+			fmt.Fprintln(os.Stderr, "found no definition for "+qn)
 		}
 
 		if t != nil {
-			p.diagnostics = append(p.diagnostics, Diagnostic{
-				Level:    level,
-				TargetID: t.ID(),
-				Text:     aliasFor + originalName,
-			})
+			// prefer the alias's doc comment to the doc comment of the aliased type
+			if d, ok := p.aliasDocs[alias]; ok {
+				doc = d
+			}
+			p.c.setDoc(alias, doc)
+			p.resolvedAliases[alias] = resolvedAlias{id: t.ID(), original: originalName, external: external, fieldTypes: fieldTypes}
 		}
 	}
 
@@ -9,9 +9,12 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -20,23 +23,77 @@ import (
 
 // diagnostic messages
 const (
-	aliasFor               = "Alias for "
-	missingAliasFor        = "missing alias for nested type "
-	embedsUnexportedStruct = "Anonymously embeds unexported struct "
-	sealedInterface        = "Applications can't implement this interface"
+	aliasFor                = "Alias for "
+	missingAliasFor         = "missing alias for nested type "
+	embedsUnexportedStruct  = "Anonymously embeds unexported struct "
+	sealedInterface         = "Applications can't implement this interface"
+	deprecatedAPI           = "Deprecated API: "
+	deprecatedNoReplacement = "Deprecation notice doesn't suggest a replacement: "
+	platformSpecific        = "Available only on some platforms: "
+	platformVariants        = "Declared differently on some platforms: "
+	missingJSONTag          = "Field of a JSON model has no json tag, so its wire name is its Go name: "
+	conflictingWireNames    = "Fields have the same wire name: "
+	ignoredTag              = "Tag is ignored because the type has custom marshaling: "
+	skippedPackage          = "Skipped files declaring another package: "
+	clientConstructor       = "Client doesn't follow constructor conventions: "
+	clientMethod            = "Client method doesn't follow options and response conventions: "
+	orphanedOptionsResponse = "Options or response type isn't used by any client method: "
+	contextFirstErrorLast   = "Signature doesn't follow context and error conventions: "
+	pagerPoller             = "Method doesn't follow pager and poller conventions: "
+	enumValues              = "Enum's possible values are incomplete or inconsistent: "
 )
 
+// replacementRgx matches a replacement suggested by a deprecation notice such as "Use [NewFoo] instead.": a phrase
+// like "use" or "replaced by" followed by a doc link, a code span, or an identifier having an uppercase letter or a dot
+var replacementRgx = regexp.MustCompile("(?i:\\b(?:use|prefer|replaced by|superseded by|instead,?(?: \\w+)?)\\s+(?:(?:the|a|an)\\s+)?)" +
+	"(?:\\[[^\\]]+\\]|`[^`]+`|\\w*[A-Z][\\w.]*|\\w+\\.\\w[\\w.]*)")
+
+// negationRgx matches words negating a suggestion e.g. "Do not use [Foo]."
+var negationRgx = regexp.MustCompile(`(?i)\b(not|don't|never|no longer|shouldn't|cannot|can't)\b`)
+
+// clauseRgx matches the punctuation separating sentences and clauses
+var clauseRgx = regexp.MustCompile(`[.,;:]\s`)
+
 var ErrNoPackages = errors.New("no packages found")
 
+// skippedPackagesError is ErrNoPackages for a directory having files only of packages that aren't libraries
+type skippedPackagesError struct {
+	// dir is the directory's name relative to its module, for example "azcore/gen"
+	dir string
+	// skipped describes the directory's packages as Pkg.skippedPackages does
+	skipped []string
+}
+
+func (e *skippedPackagesError) Error() string {
+	return fmt.Sprintf("%s in %s; skipped %s", ErrNoPackages, e.dir, strings.Join(e.skipped, ", "))
+}
+
+func (e *skippedPackagesError) Unwrap() error {
+	return ErrNoPackages
+}
+
 // Pkg represents a Go package.
 type Pkg struct {
-	modulePath  string
+	modulePath string
+	// build selects the package's files by their build constraints
+	build       buildConfig
 	c           content
 	diagnostics []Diagnostic
 	files       map[string][]byte
 	fs          *token.FileSet
-	p           *ast.Package
-	relName     string
+	// info holds type information when the package has been type checked, otherwise it's nil
+	info *types.Info
+	p    *ast.Package
+	// typesPkg is the type checked package, when info isn't nil
+	typesPkg *types.Package
+	relName  string
+
+	// aliasDocs maps the names of types in typeAliases to their doc comments
+	aliasDocs map[string][]string
+
+	// aliasSources maps the import paths of packages defining types in typeAliases to those packages, whose
+	// files declare the definitions hoisted into this package
+	aliasSources map[string]*Pkg
 
 	// typeAliases keys are the names of types defined in other packages which this package exports by alias.
 	// For example, package "azcore" may export TokenCredential from azcore/internal/shared with
@@ -44,22 +101,41 @@ type Pkg struct {
 	// have key "TokenCredential" with value "azcore/internal/shared.TokenCredential"
 	typeAliases map[string]string
 
+	// platformNotes maps the IDs of exports that aren't available on all platforms, or whose
+	// declarations differ by platform, to comments noting the platforms declaring them
+	platformNotes map[string]string
+
+	// platformExports maps the IDs of exports declared in platform specific files to the platforms declaring them
+	platformExports map[string]platformAvailability
+
+	// resolvedAliases maps the names of types in typeAliases whose definitions have been hoisted into the package to
+	// information about the aliased types
+	resolvedAliases map[string]resolvedAlias
+
+	// skippedPackages describes packages other than this one having files in the package's directory
+	skippedPackages []string
+
 	// types maps the name of a type defined in this package to that type's definition
 	types map[string]typeDef
 }
 
-// NewPkg loads the package in the specified directory.
-// It's required there is only one package in the directory.
-func NewPkg(dir, modulePath string) (*Pkg, error) {
+// NewPkg loads the package in the specified directory, excluding files that don't build on any of
+// the build configuration's platforms. It's required there is only one package in the directory.
+func NewPkg(dir, modulePath string, build buildConfig) (*Pkg, error) {
 	pk := &Pkg{
-		modulePath:  modulePath,
-		c:           newContent(),
-		diagnostics: []Diagnostic{},
-		typeAliases: map[string]string{},
-		types:       map[string]typeDef{},
-	}
-	modulePathWithoutVersion := strings.TrimSuffix(versionReg.ReplaceAllString(modulePath, "/"), "/")
-	moduleName := filepath.Base(modulePathWithoutVersion)
+		modulePath:      modulePath,
+		build:           build,
+		c:               newContent(),
+		diagnostics:     []Diagnostic{},
+		aliasDocs:       map[string][]string{},
+		aliasSources:    map[string]*Pkg{},
+		platformNotes:   map[string]string{},
+		platformExports: map[string]platformAvailability{},
+		resolvedAliases: map[string]resolvedAlias{},
+		typeAliases:     map[string]string{},
+		types:           map[string]typeDef{},
+	}
+	moduleName := baseModuleName(modulePath)
 	if _, after, found := strings.Cut(dir, moduleName); found {
 		pk.relName = moduleName
 		if after != "" {
@@ -73,17 +149,17 @@ func NewPkg(dir, modulePath string) (*Pkg, error) {
 	pk.fs = token.NewFileSet()
 	packages, err := parser.ParseDir(pk.fs, dir, func(f os.FileInfo) bool {
 		// exclude test files
-		return !strings.HasSuffix(f.Name(), "_test.go")
-	}, 0)
+		return !strings.HasSuffix(f.Name(), "_test.go") && build.includeFile(dir, f.Name())
+	}, parser.ParseComments)
 	if err != nil {
 		return nil, err
 	}
-	if ps := len(packages); ps != 1 {
-		err = ErrNoPackages
-		if ps > 1 {
-			err = fmt.Errorf(`found %d packages in "%s"`, ps, dir)
+	pk.skipPackages(dir, packages)
+	if len(packages) != 1 {
+		if len(pk.skippedPackages) > 0 {
+			return nil, &skippedPackagesError{dir: pk.relName, skipped: pk.skippedPackages}
 		}
-		return nil, err
+		return nil, ErrNoPackages
 	}
 	for _, p := range packages {
 		pk.p = p
@@ -93,16 +169,77 @@ func NewPkg(dir, modulePath string) (*Pkg, error) {
 	panic("failed to load package")
 }
 
+// skipPackages removes all but the library package from packages parsed from dir, recording each removed
+// package. Non-library packages are main packages, such as code generators, and external test packages in
+// files lacking the "_test.go" suffix. When dir has several library packages, the one named like dir is kept,
+// or else the one having the most files.
+func (pk *Pkg) skipPackages(dir string, packages map[string]*ast.Package) {
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	library := ""
+	for _, name := range names {
+		if name == "main" || strings.HasSuffix(name, "_test") {
+			continue
+		}
+		if library == "" || name == filepath.Base(dir) || library != filepath.Base(dir) && len(packages[name].Files) > len(packages[library].Files) {
+			library = name
+		}
+	}
+	// when library is "", there's nothing to review and every package is removed
+	for _, name := range names {
+		if name == library {
+			continue
+		}
+		files := make([]string, 0, len(packages[name].Files))
+		for f := range packages[name].Files {
+			files = append(files, filepath.Base(f))
+		}
+		sort.Strings(files)
+		pk.skippedPackages = append(pk.skippedPackages, fmt.Sprintf("package %s (%s)", name, strings.Join(files, ", ")))
+		delete(packages, name)
+	}
+}
+
 // Name returns the package's name relative to its module, for example "azcore/runtime".
 func (pkg Pkg) Name() string {
 	return pkg.relName
 }
 
 // Index parses the package's files, adding exported types to the package's content as discovered.
+// Files that build only on some platforms are indexed separately, so that their exports can be annotated with
+// those platforms, and so that variants of an export declared for different platforms don't overwrite each other.
 func (p *Pkg) Index() {
-	for _, f := range p.p.Files {
+	names := make([]string, 0, len(p.p.Files))
+	for name := range p.p.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	specific := []string{}
+	for _, name := range names {
+		f := p.p.Files[name]
+		if p.build.match(fileConstraint(filepath.Base(name), f)) == p.build.all() {
+			p.indexFile(f)
+		} else {
+			specific = append(specific, name)
+		}
+	}
+	variants := map[string][]platformVariant{}
+	for _, name := range specific {
+		f := p.p.Files[name]
+		expr := fileConstraint(filepath.Base(name), f)
+		c := p.c
+		p.c = newContent()
 		p.indexFile(f)
+		fc := p.c
+		p.c = c
+		p.c.mergePlatformContent(fc, expr.String(), p.build.match(expr), variants)
 	}
+	p.annotatePlatformVariants(variants)
+	// consts can refer to consts declared later or in other files, so evaluate them after indexing all files
+	p.c.evaluateConsts(*p)
 }
 
 func (p *Pkg) indexFile(f *ast.File) {
@@ -124,90 +261,137 @@ func (p *Pkg) indexFile(f *ast.File) {
 			// children can't be exported, let's not inspect them
 			return false
 		case *ast.GenDecl:
+			if x.Doc != nil && len(x.Specs) == 1 {
+				// the doc comment of an ungrouped declaration like "type Foo struct{}"
+				// belongs to the GenDecl rather than the spec
+				switch spec := x.Specs[0].(type) {
+				case *ast.TypeSpec:
+					if spec.Doc == nil {
+						spec.Doc = x.Doc
+					}
+				case *ast.ValueSpec:
+					if spec.Doc == nil {
+						spec.Doc = x.Doc
+					}
+				}
+			}
 			if x.Tok == token.CONST || x.Tok == token.VAR {
 				// const or var declaration
-				for _, s := range x.Specs {
-					p.c.addGenDecl(*p, x.Tok, s.(*ast.ValueSpec), imports)
-				}
+				p.c.addGenDecl(*p, x, imports)
 			}
 		case *ast.TypeSpec:
 			switch t := x.Type.(type) {
 			case *ast.ArrayType:
 				// "type UUID [16]byte"
-				txt := p.getText(t.Pos(), t.End())
 				p.types[x.Name.Name] = typeDef{n: x, p: p}
-				p.c.addSimpleType(*p, x.Name.Name, p.Name(), txt, imports)
+				p.c.addSimpleTypeExpr(*p, p.Name(), x, imports)
 			case *ast.FuncType:
 				// "type PolicyFunc func(*Request) (*http.Response, error)"
-				txt := p.getText(t.Pos(), t.End())
 				p.types[x.Name.Name] = typeDef{n: x, p: p}
-				p.c.addSimpleType(*p, x.Name.Name, p.Name(), txt, imports)
+				p.c.addSimpleTypeExpr(*p, p.Name(), x, imports)
 			case *ast.Ident:
 				// "type ETag string"
 				p.types[x.Name.Name] = typeDef{n: x, p: p}
-				p.c.addSimpleType(*p, x.Name.Name, p.Name(), t.Name, imports)
+				p.c.addSimpleTypeExpr(*p, p.Name(), x, imports)
 			case *ast.IndexExpr, *ast.IndexListExpr:
 				// "type Client GenericClient[BaseClient]"
 				// "type Client CompositeClient[BaseClient1, BaseClient2]"
-				txt := p.getText(t.Pos(), t.End())
 				p.types[x.Name.Name] = typeDef{n: x, p: p}
-				p.c.addSimpleType(*p, x.Name.Name, p.Name(), txt, imports)
+				p.c.addSimpleTypeExpr(*p, p.Name(), x, imports)
 			case *ast.InterfaceType:
 				p.types[x.Name.Name] = typeDef{n: x, p: p}
-				in := p.c.addInterface(*p, x.Name.Name, p.Name(), t, imports)
-				if in.Sealed {
-					p.diagnostics = append(p.diagnostics, Diagnostic{
-						TargetID: in.ID(),
-						Level:    DiagnosticLevelInfo,
-						Text:     sealedInterface,
-					})
-				}
+				p.c.addInterface(*p, x.Name.Name, p.Name(), x, imports)
 			case *ast.MapType:
 				// "type opValues map[reflect.Type]interface{}"
-				txt := p.getText(t.Pos(), t.End())
-				p.c.addSimpleType(*p, x.Name.Name, p.Name(), txt, imports)
+				p.c.addSimpleTypeExpr(*p, p.Name(), x, imports)
 			case *ast.SelectorExpr:
 				if ident, ok := t.X.(*ast.Ident); ok {
 					if impPath, ok := imports[ident.Name]; ok {
 						// alias in the same module could use type navigator directly
 						if _, _, found := strings.Cut(impPath, p.modulePath); found && !strings.Contains(impPath, "internal") {
-							expr := p.getText(t.Pos(), t.End())
-							p.c.addSimpleType(*p, x.Name.Name, p.Name(), expr, imports)
+							p.c.addSimpleTypeExpr(*p, p.Name(), x, imports)
 						}
 
 						// This is a re-exported type e.g. "type TokenCredential = shared.TokenCredential".
 						// Track it as an alias so we can later hoist its definition into this package.
 						qn := impPath + "." + t.Sel.Name
 						p.typeAliases[x.Name.Name] = qn
+						if doc := docLines(x.Doc); doc != nil {
+							p.aliasDocs[x.Name.Name] = doc
+						}
 					} else {
 						// Non-SDK underlying type e.g. "type EDMDateTime time.Time". Handle it like a simple type
 						// because we don't want to hoist its definition into this package.
-						expr := p.getText(t.Pos(), t.End())
-						p.c.addSimpleType(*p, x.Name.Name, p.Name(), expr, imports)
+						p.c.addSimpleTypeExpr(*p, p.Name(), x, imports)
 					}
 				}
 			case *ast.StructType:
 				p.types[x.Name.Name] = typeDef{n: x, p: p}
-				s := p.c.addStruct(*p, x.Name.Name, p.Name(), x, imports)
-				for _, t := range s.AnonymousFields {
-					// if t contains "." it must be exported
-					if !strings.Contains(t, ".") && unicode.IsLower(rune(t[0])) {
-						p.diagnostics = append(p.diagnostics, Diagnostic{
-							Level:    DiagnosticLevelError,
-							TargetID: s.ID(),
-							Text:     embedsUnexportedStruct + t,
-						})
-					}
-				}
+				p.c.addStruct(*p, x.Name.Name, p.Name(), x, imports)
 			default:
 				txt := p.getText(x.Pos(), x.End())
-				fmt.Printf("unhandled node type %T: %s\n", t, txt)
+				fmt.Fprintf(os.Stderr, "unhandled node type %T: %s\n", t, txt)
 			}
+			if doc := docLines(x.Doc); doc != nil {
+				p.c.setDoc(x.Name.Name, doc)
+			}
+			p.c.setPosition(x.Name.Name, p.fs.Position(x.Name.Pos()))
 		}
 		return true
 	})
 }
 
+// deprecations maps the IDs of exported APIs having a deprecation notice to that notice
+func (p *Pkg) deprecations() map[string]string {
+	notices := map[string]string{}
+	check := func(id string, doc []string) {
+		if notice, ok := deprecationNotice(doc); ok {
+			notices[id] = notice
+		}
+	}
+	for _, c := range p.c.Consts {
+		if c.Exported() {
+			check(c.ID(), c.doc)
+		}
+	}
+	for _, f := range p.c.Funcs {
+		if f.Exported() {
+			check(f.ID(), f.doc)
+		}
+	}
+	for _, i := range p.c.Interfaces {
+		if i.Exported() {
+			check(i.ID(), i.doc)
+			for name, m := range i.methods {
+				if unicode.IsUpper(rune(name[0])) {
+					check(m.ID(), m.doc)
+				}
+			}
+		}
+	}
+	for _, t := range p.c.SimpleTypes {
+		if t.Exported() {
+			check(t.ID(), t.doc)
+		}
+	}
+	for _, s := range p.c.Structs {
+		if s.Exported() {
+			check(s.ID(), s.doc)
+			for name, doc := range s.fieldDocs {
+				if unicode.IsUpper(rune(name[0])) {
+					check(name+"-"+s.ID(), doc)
+				}
+			}
+		}
+	}
+	for _, v := range p.c.Vars {
+		if v.Exported() {
+			check(v.ID(), v.doc)
+		}
+	}
+	return notices
+}
+
 // returns the text between [start, end]
 func (pkg Pkg) getText(start token.Pos, end token.Pos) string {
 	// convert to absolute position within the containing file
@@ -224,11 +408,11 @@ func (pkg Pkg) getText(start token.Pos, end token.Pos) string {
 }
 
 // iterates over the specified field list, for each field the specified
-// callback is invoked with the name of the field and the type name.  the field
+// callback is invoked with the name of the field and the type expression.  the field
 // name can be nil, e.g. anonymous fields in structs, unnamed return types etc.
-func (pkg Pkg) translateFieldList(fl []*ast.Field, cb func(*string, string)) {
+func (pkg Pkg) translateFieldList(fl []*ast.Field, cb func(*string, ast.Expr)) {
 	for _, f := range fl {
-		t := pkg.getText(f.Type.Pos(), f.Type.End())
+		t := f.Type
 		if len(f.Names) == 0 {
 			// field is an unnamed func return or anonymously embedded
 			cb(nil, t)
@@ -242,56 +426,141 @@ func (pkg Pkg) translateFieldList(fl []*ast.Field, cb func(*string, string)) {
 	}
 }
 
-// translateType change type string and add navigator mark:
-// 1. type in the same package or module, add navigator prefix <navigator> to the type string
-// 2. type in different module or system type, do nothing
-func (pkg Pkg) translateType(oriVal string, imports map[string]string) string {
-	now := ""
-	result := ""
-	for _, ch := range oriVal {
-		switch string(ch) {
-		case "*", "[", "]", " ", "(", ")", "{", "}", ",":
-			if now != "" {
-				result += pkg.addTypeNavigator(now, imports)
-				now = ""
-			}
-			result += string(ch)
-		case ".":
-			if now == ".." {
-				result += "..."
-				now = ""
-			} else {
-				now = now + "."
+// translateType returns the typeExpr for a type's source text. Identifiers denoting types defined in the
+// same package or module link to those types. Text that isn't a type expression, such as an import path,
+// has one token.
+func (pkg Pkg) translateType(oriVal string, imports map[string]string) typeExpr {
+	expr, err := parser.ParseExpr(oriVal)
+	if err != nil {
+		return textType(oriVal)
+	}
+	if t, ok := exprType(expr, pkg.spelledNavigator(imports)); ok {
+		return t
+	}
+	return textType(oriVal)
+}
+
+// spelledNavigator returns a func returning the navigation target of an identifier by its spelling
+func (pkg Pkg) spelledNavigator(imports map[string]string) func(ast.Expr) string {
+	return func(e ast.Expr) string {
+		switch x := e.(type) {
+		case *ast.Ident:
+			return pkg.typeNavigator(x.Name, imports)
+		case *ast.SelectorExpr:
+			if id, ok := x.X.(*ast.Ident); ok {
+				return pkg.typeNavigator(id.Name+"."+x.Sel.Name, imports)
 			}
-		default:
-			now = now + string(ch)
 		}
+		return ""
+	}
+}
+
+// typeNavigator returns the navigation target of a type name by its spelling e.g. "pkg.Foo" for "Foo", or ""
+// for predeclared types and types defined outside the module
+func (pkg Pkg) typeNavigator(name string, imports map[string]string) string {
+	if slices.Contains(keywords, name) || slices.Contains(internalTypes, name) {
+		return ""
+	}
+	qualifier, typeName, qualified := strings.Cut(name, ".")
+	if !qualified {
+		return pkg.Name() + "." + name
 	}
-	if now != "" {
-		result += pkg.addTypeNavigator(now, imports)
+	// find exact import path of a type
+	if impPath, ok := imports[qualifier]; ok {
+		// judge if import path is in the module
+		if _, after, found := strings.Cut(impPath, pkg.modulePath); found {
+			return path.Base(pkg.modulePath) + after + "." + typeName
+		}
 	}
-	return result
+	return ""
 }
 
-func (pkg Pkg) addTypeNavigator(oriVal string, imports map[string]string) string {
-	switch {
-	case slices.Contains(keywords, oriVal) || slices.Contains(internalTypes, oriVal):
-		return oriVal
-	default:
-		splits := strings.Split(oriVal, ".")
-		if len(splits) == 1 {
-			return fmt.Sprintf("<%s.%s>%s", pkg.Name(), oriVal, oriVal)
-		} else {
-			// find exact import path of a type
-			if impPath, ok := imports[splits[0]]; ok {
-				// judge if import path is in the module
-				if _, after, found := strings.Cut(impPath, pkg.modulePath); found {
-					return fmt.Sprintf("<%s.%s>%s", path.Base(pkg.modulePath)+after, splits[1], oriVal)
+// translateExpr is translateType for a type expression. When the package has been type checked, identifiers
+// are resolved to the objects they denote, so that only those denoting named types defined in this module
+// link to them. Identifiers type checking couldn't resolve, for example because an imported package
+// couldn't be loaded, fall back to translateType's handling.
+func (pkg Pkg) translateExpr(expr ast.Expr, imports map[string]string) typeExpr {
+	nav := pkg.spelledNavigator(imports)
+	if pkg.info != nil {
+		spelled := nav
+		nav = func(e ast.Expr) string {
+			switch x := e.(type) {
+			case *ast.Ident:
+				if obj, ok := pkg.info.Uses[x]; ok {
+					return pkg.navigator(obj)
+				}
+			case *ast.SelectorExpr:
+				if obj, ok := pkg.info.Uses[x.Sel]; ok {
+					return pkg.navigator(obj)
 				}
 			}
-			return oriVal
+			return spelled(e)
 		}
 	}
+	if t, ok := exprType(expr, nav); ok {
+		return t
+	}
+	return textType(pkg.getText(expr.Pos(), expr.End()))
+}
+
+// stripTypeParams removes the links translateExpr makes for the given type parameters. Without type
+// checking, translateExpr can't distinguish a type parameter from a type defined in the package.
+func (pkg Pkg) stripTypeParams(t typeExpr, names []string) typeExpr {
+	if len(names) == 0 {
+		return t
+	}
+	targets := map[string]bool{}
+	for _, name := range names {
+		targets[pkg.Name()+"."+name] = true
+	}
+	return t.unlink(targets)
+}
+
+// navigator returns the navigation target of an identifier denoting obj. Only named types defined in
+// this module have one; type parameters, predeclared types and values such as parameters don't.
+func (pkg Pkg) navigator(obj types.Object) string {
+	tn, ok := obj.(*types.TypeName)
+	if !ok || tn.Pkg() == nil {
+		return ""
+	}
+	if _, ok := tn.Type().(*types.TypeParam); ok {
+		return ""
+	}
+	after, found := strings.CutPrefix(tn.Pkg().Path(), pkg.modulePath)
+	if !found || (after != "" && after[0] != '/') {
+		return ""
+	}
+	return baseModuleName(pkg.modulePath) + after + "." + tn.Name()
+}
+
+// importPath returns the package's import path
+func (p Pkg) importPath() string {
+	return p.modulePath + strings.TrimPrefix(p.relName, baseModuleName(p.modulePath))
+}
+
+// typeCheck type checks the package, enabling translateExpr to resolve identifiers, and returns the
+// checked package. Type errors, for example due to dependencies that can't be imported, are tolerated.
+func (p *Pkg) typeCheck(imp types.Importer) (*types.Package, error) {
+	if p.info != nil {
+		if p.typesPkg == nil {
+			return nil, fmt.Errorf("import cycle through %s", p.importPath())
+		}
+		return p.typesPkg, nil
+	}
+	names := make([]string, 0, len(p.p.Files))
+	for name := range p.p.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	files := make([]*ast.File, 0, len(names))
+	for _, name := range names {
+		files = append(files, p.p.Files[name])
+	}
+	p.info = &types.Info{Defs: map[*ast.Ident]types.Object{}, Uses: map[*ast.Ident]types.Object{}}
+	conf := types.Config{Importer: imp, Error: func(error) {}}
+	// Check returns the first type error, which Error has already tolerated
+	p.typesPkg, _ = conf.Check(p.importPath(), p.fs, files, p.info)
+	return p.typesPkg, nil
 }
 
 // TODO: could be replaced by TokenMaker
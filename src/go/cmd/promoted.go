@@ -0,0 +1,193 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// promotedMember is a field or method an embedded type promotes to a struct or interface
+type promotedMember struct {
+	// from is the selector path of the embedded field declaring the member e.g. "Model.Inner"
+	from string
+	// method is the promoted method, when the member isn't a field
+	method *Func
+	name   string
+	// typ is a promoted field's type
+	typ typeExpr
+}
+
+// embeddedType is a type embedded, directly or through other embedded types, in a struct or interface
+type embeddedType struct {
+	// path is the selector path of the embedded field e.g. "Model.Inner"
+	path string
+	// typ is the embedded type
+	typ typeExpr
+	// inInterface is true when the type is embedded in an interface rather than in a struct
+	inInterface bool
+}
+
+// promotable is a struct or interface that can be embedded
+type promotable struct {
+	// embedded lists the type's embedded types
+	embedded []typeExpr
+	// fields maps the type's field names to their types
+	fields map[string]typeExpr
+	// isInterface is true for interfaces, whose embedded types aren't fields
+	isInterface bool
+	methods     map[string]Func
+}
+
+// resolvePromotedMembers finds the members promoted to each exported struct and interface in
+// the module by embedded types defined in the module, including types hoisted from aliases.
+// Call it after resolving aliases and before rendering, which consumes package content.
+func (m *Module) resolvePromotedMembers() {
+	types := map[string]promotable{}
+	for _, p := range m.packages {
+		for _, s := range p.c.Structs {
+			types[s.ID()] = promotable{embedded: s.embedded, fields: s.fields, methods: exportedMethods(p.c.findMethods(s.Name()))}
+		}
+		for _, i := range p.c.Interfaces {
+			types[i.ID()] = promotable{embedded: i.embeddedInterfaces, isInterface: true, methods: i.methods}
+		}
+	}
+	for _, p := range m.packages {
+		for name, s := range p.c.Structs {
+			if !s.Exported() {
+				continue
+			}
+			declared := map[string]bool{}
+			for f := range s.fields {
+				declared[f] = true
+			}
+			for _, e := range s.embedded {
+				declared[embeddedFieldName(e)] = true
+			}
+			for _, f := range p.c.findMethods(name) {
+				declared[f.Name()] = true
+			}
+			p.c.setPromoted(name, promotedMembers(types, s.embedded, false, declared))
+		}
+		for name, i := range p.c.Interfaces {
+			if !i.Exported() {
+				continue
+			}
+			declared := map[string]bool{}
+			for m := range i.methods {
+				declared[m] = true
+			}
+			p.c.setPromoted(name, promotedMembers(types, i.embeddedInterfaces, true, declared))
+		}
+	}
+}
+
+// promotedMembers returns the exported members promoted by the given embedded types of a struct, or
+// of an interface when isInterface is true, following Go's rules: a member at a shallower depth shadows
+// members of the same name at greater depths, and members of the same name at the same depth cancel
+// each other. A type embedded in structs through several paths of the same depth promotes its members
+// once per path, so they cancel each other, whereas interfaces embedding the same interface share its
+// methods. declared names aren't promoted.
+func promotedMembers(types map[string]promotable, embedded []typeExpr, isInterface bool, declared map[string]bool) []promotedMember {
+	members := []promotedMember{}
+	level := make([]embeddedType, 0, len(embedded))
+	for _, e := range embedded {
+		level = append(level, embeddedType{path: embeddedFieldName(e), typ: e, inInterface: isInterface})
+	}
+	// visited holds the IDs of the types expanded at shallower depths, whose members shadow those of deeper paths
+	visited := map[string]bool{}
+	for len(level) > 0 {
+		found := map[string][]promotedMember{}
+		next := []embeddedType{}
+		// expanded holds the IDs of the types expanded at this depth
+		expanded := map[string]bool{}
+		for _, e := range level {
+			id := e.typ.navigator()
+			t, ok := types[id]
+			if !ok || visited[id] || expanded[id] && e.inInterface {
+				continue
+			}
+			expanded[id] = true
+			for name, typ := range t.fields {
+				found[name] = append(found[name], promotedMember{from: e.path, name: name, typ: typ})
+			}
+			for name, f := range t.methods {
+				method := f
+				found[name] = append(found[name], promotedMember{from: e.path, method: &method, name: name})
+			}
+			for _, inner := range t.embedded {
+				name := embeddedFieldName(inner)
+				if !t.isInterface {
+					found[name] = append(found[name], promotedMember{from: e.path, name: name, typ: inner})
+				}
+				next = append(next, embeddedType{path: e.path + "." + name, typ: inner, inInterface: t.isInterface})
+			}
+		}
+		for id := range expanded {
+			visited[id] = true
+		}
+		for name, ms := range found {
+			if declared[name] {
+				continue
+			}
+			// shallower members shadow deeper ones even when they're ambiguous
+			declared[name] = true
+			if len(ms) == 1 && unicode.IsUpper(rune(name[0])) {
+				members = append(members, ms[0])
+			}
+		}
+		level = next
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].name < members[j].name
+	})
+	return members
+}
+
+// embeddedFieldName returns the field name of an embedded type e.g. "Model" for "*pkg.Model"
+func embeddedFieldName(typ typeExpr) string {
+	name := strings.TrimPrefix(typ.text, "*")
+	if before, _, found := strings.Cut(name, "["); found {
+		name = before
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// exportedMethods returns the exported methods of a findMethods result keyed by name
+func exportedMethods(methods map[string]Func) map[string]Func {
+	result := map[string]Func{}
+	for _, f := range methods {
+		if f.Exported() {
+			result[f.Name()] = f
+		}
+	}
+	return result
+}
+
+// makePromotedLines returns lines listing the members promoted to the type having the given ID, nested under a header
+func makePromotedLines(id string, members []promotedMember) []tokenLine {
+	defID := id + "-promoted"
+	header := &[]Token{}
+	makeToken(&defID, nil, "", TokenTypeLineIDMarker, header)
+	makeToken(nil, nil, "// promoted fields and methods", TokenTypeComment, header)
+	lines := []tokenLine{}
+	for _, m := range members {
+		list := &[]Token{}
+		makeToken(nil, nil, m.name, TokenTypeMemberName, list)
+		if m.method != nil {
+			m.method.makeSignatureTokens(list)
+		} else {
+			makeToken(nil, nil, " ", TokenTypeWhitespace, list)
+			m.typ.makeTokens(list)
+		}
+		makeToken(nil, nil, " ", TokenTypeWhitespace, list)
+		makeToken(nil, nil, "// from "+m.from, TokenTypeComment, list)
+		lines = append(lines, tokenLine{tokens: *list})
+	}
+	return []tokenLine{{tokens: *header, children: lines}, blankLine}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+// renderClasses maps flat token types to the render class of the equivalent tree token
+var renderClasses = map[TokenType]string{
+	TokenTypeText:          "text",
+	TokenTypePunctuation:   "punc",
+	TokenTypeKeyword:       "keyword",
+	TokenTypeTypeName:      "tname",
+	TokenTypeMemberName:    "mname",
+	TokenTypeStringLiteral: "sliteral",
+	TokenTypeLiteral:       "literal",
+	TokenTypeComment:       "comment",
+}
+
+// reviewTokenKinds maps flat token types to tree token kinds
+var reviewTokenKinds = map[TokenType]ReviewTokenKind{
+	TokenTypeText:          ReviewTokenKindText,
+	TokenTypePunctuation:   ReviewTokenKindPunctuation,
+	TokenTypeKeyword:       ReviewTokenKindKeyword,
+	TokenTypeTypeName:      ReviewTokenKindTypeName,
+	TokenTypeMemberName:    ReviewTokenKindMemberName,
+	TokenTypeStringLiteral: ReviewTokenKindStringLiteral,
+	TokenTypeLiteral:       ReviewTokenKindLiteral,
+	TokenTypeComment:       ReviewTokenKindComment,
+}
+
+// NewCodeFile converts a review to the tree token format
+func NewCodeFile(review PackageReview) CodeFile {
+	return CodeFile{
+		Diagnostics: review.Diagnostics,
+		Language:    review.Language,
+		Navigation:  review.Navigation,
+		PackageName: review.PackageName,
+		ReviewLines: newReviewLines(review.lines, review.Navigation),
+	}
+}
+
+// tokenLine is a line of a review as token makers make it: the line's tokens, without indentation or a newline,
+// and the lines nested under it, such as a struct's fields. A line having no tokens is blank.
+type tokenLine struct {
+	tokens   []Token
+	children []tokenLine
+	// closing is true for a line closing the block opened by the line before it e.g. a struct's "}"
+	closing bool
+}
+
+// blankLine separates top level declarations
+var blankLine = tokenLine{}
+
+// flattenLines appends the flat token stream of lines to list, indenting nested lines with tabs
+func flattenLines(lines []tokenLine, list *[]Token) {
+	var flatten func([]tokenLine, int)
+	flatten = func(lines []tokenLine, depth int) {
+		for _, l := range lines {
+			if len(l.tokens) > 0 {
+				for i := 0; i < depth; i++ {
+					makeToken(nil, nil, "\t", TokenTypeWhitespace, list)
+				}
+				*list = append(*list, l.tokens...)
+			}
+			makeToken(nil, nil, "", TokenTypeNewline, list)
+			flatten(l.children, depth+1)
+		}
+	}
+	flatten(lines, 0)
+}
+
+// flatTokens returns the flat token stream of lines
+func flatTokens(lines []tokenLine) []Token {
+	list := &[]Token{}
+	flattenLines(lines, list)
+	return *list
+}
+
+// newReviewLines converts lines to review lines, converting whitespace tokens to suffix spaces on the tokens
+// preceding them. Navigation provides the kinds of types defined in the lines.
+func newReviewLines(lines []tokenLine, nav []Navigation) []ReviewLine {
+	typeKinds := map[string]string{}
+	var collectKinds func([]Navigation)
+	collectKinds = func(items []Navigation) {
+		for _, n := range items {
+			if n.Tags != nil {
+				if kind, ok := (*n.Tags)["TypeKind"]; ok {
+					typeKinds[n.NavigationId] = kind
+				}
+			}
+			collectKinds(n.ChildItems)
+		}
+	}
+	collectKinds(nav)
+
+	// deprecated counts the open deprecated ranges, which can span lines and nest e.g. a deprecated
+	// field of a deprecated struct. Lines are converted in document order, nested lines after their parent.
+	deprecated := 0
+	var convert func([]tokenLine) []ReviewLine
+	convert = func(lines []tokenLine) []ReviewLine {
+		result := make([]ReviewLine, 0, len(lines))
+		for i, l := range lines {
+			rl := ReviewLine{Tokens: []ReviewToken{}}
+			for _, t := range l.tokens {
+				switch t.Kind {
+				case TokenTypeDeprecatedRangeStart:
+					deprecated++
+				case TokenTypeDeprecatedRangeEnd:
+					deprecated--
+				case TokenTypeWhitespace:
+					if len(rl.Tokens) > 0 {
+						rl.Tokens[len(rl.Tokens)-1].HasSuffixSpace = true
+					}
+				case TokenTypeLineIDMarker:
+					if t.DefinitionID != nil && rl.LineID == "" {
+						rl.LineID = *t.DefinitionID
+					}
+				default:
+					rt := ReviewToken{IsDeprecated: deprecated > 0, Kind: reviewTokenKinds[t.Kind], Value: t.Value}
+					if class, ok := renderClasses[t.Kind]; ok {
+						rt.RenderClasses = []string{class}
+					}
+					if t.NavigateToID != nil {
+						rt.NavigateToID = *t.NavigateToID
+					}
+					if t.DefinitionID != nil {
+						if rl.LineID == "" {
+							rl.LineID = *t.DefinitionID
+						}
+						if kind, ok := typeKinds[*t.DefinitionID]; ok {
+							rt.NavigationDisplayName = t.Value
+							rt.RenderClasses = append(rt.RenderClasses, kind)
+						}
+					}
+					rl.Tokens = append(rl.Tokens, rt)
+				}
+			}
+			rl.IsContextEndLine = l.closing && i > 0 && len(lines[i-1].children) > 0
+			if len(l.children) > 0 {
+				rl.Children = convert(l.children)
+			}
+			result = append(result, rl)
+		}
+		return result
+	}
+	result := convert(lines)
+	relateCommentLines(result)
+	return result
+}
+
+// relateCommentLines relates lines containing only comments to the next line, in document order, defining
+// something, so that APIView keeps doc comments together with their declarations
+func relateCommentLines(lines []ReviewLine) {
+	ordered := []*ReviewLine{}
+	var walk func([]ReviewLine)
+	walk = func(lines []ReviewLine) {
+		for i := range lines {
+			ordered = append(ordered, &lines[i])
+			walk(lines[i].Children)
+		}
+	}
+	walk(lines)
+	for i, l := range ordered {
+		if !isCommentLine(*l) {
+			continue
+		}
+		for _, next := range ordered[i+1:] {
+			if isCommentLine(*next) {
+				continue
+			}
+			l.RelatedToLine = next.LineID
+			break
+		}
+	}
+}
+
+func isCommentLine(l ReviewLine) bool {
+	if len(l.Tokens) == 0 || l.LineID != "" {
+		return false
+	}
+	for _, t := range l.Tokens {
+		if t.Kind != ReviewTokenKindComment {
+			return false
+		}
+	}
+	return true
+}
@@ -15,7 +15,9 @@ var rootCmd = &cobra.Command{
 	Use: "apiviewgo <moduleDir> <outputDir>",
 	Long: `apiviewgo outputs a file representing the public API of an Azure SDK for Go
 module in APIView format. It writes this file to <outputDir>/<module name>.json,
-overwriting any file of the same name.`,
+or <outputDir>/<module name>.html for the html format, overwriting any file of the same name.`,
+	// without Args, cobra would treat <moduleDir> as the name of a subcommand
+	Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 2 {
 			err := cmd.Help()
@@ -24,13 +26,28 @@ overwriting any file of the same name.`,
 			}
 			return
 		}
-		err := CreateAPIView(args[0], args[1])
+		err := CreateAPIView(args[0], args[1], opts)
 		if err != nil {
 			fmt.Println(err)
 		}
 	},
 }
 
+var opts Options
+
+func init() {
+	rootCmd.Flags().StringVar((*string)(&opts.Format), "format", string(OutputFormatTokens), `output format, "tokens", "tree" or "html"`)
+	rootCmd.Flags().BoolVar(&opts.TypeCheck, "type-check", false, "resolve types with go/types for exact navigation links")
+	rootCmd.Flags().BoolVar(&opts.Promoted, "promoted", false, "list the fields and methods embedded types promote to structs and interfaces")
+	rootCmd.Flags().StringVar((*string)(&opts.Order), "order", string(OrderingKind), `order of declarations, "kind", "alpha" or "source"`)
+	rootCmd.Flags().BoolVar(&opts.StructTags, "struct-tags", false, "show struct field tags")
+	rootCmd.Flags().StringVar(&opts.GOOS, "goos", "", "index only files building for this GOOS (default all)")
+	rootCmd.Flags().StringVar(&opts.GOARCH, "goarch", "", "index only files building for this GOARCH (default all)")
+	rootCmd.Flags().StringSliceVar(&opts.Tags, "tags", nil, "comma-separated list of additional build tags to satisfy")
+	rootCmd.Flags().StringVar(&opts.Rules, "rules", "", "JSON file configuring the levels of diagnostic rules")
+	rootCmd.Flags().StringVar(&opts.SARIF, "sarif", "", "also write diagnostics to this file in SARIF format")
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
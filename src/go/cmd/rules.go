@@ -0,0 +1,327 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// rulesHelpURI is the location of the documentation of the built-in rules. Each rule's
+// documentation is the section of this document whose heading is the rule's ID.
+const rulesHelpURI = "https://github.com/Azure/azure-sdk-tools/blob/main/src/go/README.md#"
+
+// Rule checks the API of a module's packages against a guideline
+type Rule interface {
+	// ID returns the rule's stable identifier. Diagnostics, SARIF results and rule configuration refer to rules by ID.
+	ID() string
+	// Description briefly describes what the rule checks
+	Description() string
+	// HelpLinkURI returns the location of the rule's documentation, or "" when it has none
+	HelpLinkURI() string
+	// Check returns diagnostics for the API of one of the module's packages, after the module is indexed and
+	// types exported by alias are resolved. The engine sets the DiagnosticID and HelpLinkURI of the diagnostics,
+	// and overrides their Level when configured to.
+	Check(m *Module, p *Pkg) []Diagnostic
+}
+
+// rule is a built-in rule
+type rule struct {
+	id, description string
+	check           func(m *Module, p *Pkg) []Diagnostic
+}
+
+func (r rule) ID() string {
+	return r.id
+}
+
+func (r rule) Description() string {
+	return r.description
+}
+
+func (r rule) HelpLinkURI() string {
+	return rulesHelpURI + strings.ToLower(r.id)
+}
+
+func (r rule) Check(m *Module, p *Pkg) []Diagnostic {
+	return r.check(m, p)
+}
+
+// rules is the registry of rules, sorted by ID
+var rules = []Rule{
+	rule{"AliasFor", "Type is an alias for a type defined elsewhere", checkAliases},
+	rule{"ClientConstructor", "Client doesn't follow the Azure SDK's constructor conventions", checkClientConstructors},
+	rule{"ClientMethod", "Client method's options or response doesn't follow the Azure SDK's conventions", checkClientMethods},
+	rule{"ConflictingWireNames", "Struct fields serialize to the same JSON or XML name", checkConflictingWireNames},
+	rule{"ContextFirstErrorLast", "Signature doesn't take a context first or return an error last", checkContextAndErrors},
+	rule{"DeprecatedAPI", "API is deprecated", checkDeprecatedAPIs},
+	rule{"DeprecatedNoReplacement", "Deprecation notice doesn't suggest a replacement", checkDeprecationReplacements},
+	rule{"EmbedsUnexportedStruct", "Struct anonymously embeds an unexported struct", checkEmbeddedStructs},
+	rule{"EnumValues", "Enum's Possible<Type>Values func is missing or incomplete, or its consts have the same value", checkEnums},
+	rule{"IgnoredTag", "Field tag is ignored because the struct has custom marshaling", checkIgnoredTags},
+	rule{"MissingAlias", "Field type of an aliased struct has no alias", checkMissingAliases},
+	rule{"MissingJSONTag", "Field of a struct having json tags has no json tag", checkMissingJSONTags},
+	rule{"OrphanedOptionsResponse", "Options or response type isn't used by any client method", checkOrphanedOptionsResponses},
+	rule{"PagerPoller", "Method's name and pager or poller result don't follow the Azure SDK's conventions", checkPagersPollers},
+	rule{"PlatformSpecific", "API is available only on some platforms", checkPlatformSpecific},
+	rule{"PlatformVariants", "API is declared differently on some platforms", checkPlatformVariants},
+	rule{"SealedInterface", "Interface has an unexported method, so applications can't implement it", checkSealedInterfaces},
+	rule{"SkippedPackage", "Directory has files of a package other than the library package, which aren't reviewed", checkSkippedPackages},
+}
+
+// RegisterRule adds a rule to the registry, so that it checks every review generated afterward.
+// It returns an error when the rule's ID is empty or already registered.
+func RegisterRule(r Rule) error {
+	if r.ID() == "" {
+		return fmt.Errorf("rule has no ID")
+	}
+	if findRule(r.ID()) != nil {
+		return fmt.Errorf("rule %q is already registered", r.ID())
+	}
+	rules = append(rules, r)
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID() < rules[j].ID()
+	})
+	return nil
+}
+
+// findRule returns the registered rule having the given ID, or nil when there's no such rule
+func findRule(id string) Rule {
+	for _, r := range rules {
+		if r.ID() == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// ruleConfig tunes the registered rules
+type ruleConfig struct {
+	// levels maps rule IDs to the level of their diagnostics, overriding the levels the rules choose
+	levels map[string]DiagnosticLevel
+	// disabled holds the IDs of rules that don't run
+	disabled map[string]bool
+}
+
+// ruleLevels maps the levels of a rule configuration file to diagnostic levels. "off" disables a rule.
+var ruleLevels = map[string]DiagnosticLevel{
+	"info":    DiagnosticLevelInfo,
+	"warning": DiagnosticLevelWarning,
+	"error":   DiagnosticLevelError,
+}
+
+// loadRuleConfig reads a rule configuration file, a JSON object whose "rules" object maps rule IDs to
+// "info", "warning", "error" or "off" e.g. {"rules": {"SealedInterface": "off"}}. Given an empty path,
+// it returns a configuration leaving all rules at their defaults.
+func loadRuleConfig(path string) (ruleConfig, error) {
+	config := ruleConfig{levels: map[string]DiagnosticLevel{}, disabled: map[string]bool{}}
+	if path == "" {
+		return config, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	var file struct {
+		Rules map[string]string `json:"rules"`
+	}
+	if err = json.Unmarshal(b, &file); err != nil {
+		return config, fmt.Errorf("invalid rule configuration %s: %w", path, err)
+	}
+	for id, level := range file.Rules {
+		if findRule(id) == nil {
+			return config, fmt.Errorf("invalid rule configuration %s: unknown rule %q", path, id)
+		}
+		if level == "off" {
+			config.disabled[id] = true
+		} else if l, ok := ruleLevels[level]; ok {
+			config.levels[id] = l
+		} else {
+			return config, fmt.Errorf(`invalid rule configuration %s: level of %s must be "info", "warning", "error" or "off", not %q`, path, id, level)
+		}
+	}
+	return config, nil
+}
+
+// runRules checks the module's packages with the enabled rules, adding the rules' diagnostics to the packages
+func (m *Module) runRules(config ruleConfig) {
+	for _, p := range m.packages {
+		for _, r := range rules {
+			if config.disabled[r.ID()] {
+				continue
+			}
+			for _, d := range r.Check(m, p) {
+				d.DiagnosticID = r.ID()
+				d.HelpLinkURI = r.HelpLinkURI()
+				if level, ok := config.levels[r.ID()]; ok {
+					d.Level = level
+				}
+				p.diagnostics = append(p.diagnostics, d)
+			}
+		}
+	}
+}
+
+// resolvedAlias describes a type whose definition has been hoisted into a package exporting it by alias
+type resolvedAlias struct {
+	// id is the ID of the hoisted type
+	id string
+	// original is the aliased type's name, relative to the module when the type is defined in the module
+	original string
+	// external is true when the aliased type is defined in another module
+	external bool
+	// fieldTypes are the names of the types of the aliased struct's exported fields
+	fieldTypes []string
+}
+
+// checkAliases returns a diagnostic for each type exported by alias. Aliases
+// of types defined in other modules make the module's API depend on theirs.
+func checkAliases(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, a := range p.resolvedAliases {
+		level := DiagnosticLevelInfo
+		if a.external {
+			level = DiagnosticLevelWarning
+		}
+		diagnostics = append(diagnostics, Diagnostic{Level: level, TargetID: a.id, Text: aliasFor + a.original})
+	}
+	return diagnostics
+}
+
+// checkMissingAliases returns a diagnostic for each field type of a struct exported by alias which the
+// package doesn't also export by alias, making the field's type inaccessible to the package's users
+func checkMissingAliases(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, a := range p.resolvedAliases {
+		for _, t := range a.fieldTypes {
+			if _, ok := p.typeAliases[t]; !ok {
+				diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelError, TargetID: a.id, Text: missingAliasFor + t})
+			}
+		}
+	}
+	return diagnostics
+}
+
+// checkEmbeddedStructs returns a diagnostic for each unexported struct anonymously embedded in a struct
+// defined in the package
+func checkEmbeddedStructs(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for name, s := range p.c.Structs {
+		if _, ok := p.typeAliases[name]; ok {
+			continue
+		}
+		for _, e := range s.AnonymousFields {
+			// if t contains "." it must be exported
+			if t := e.text; !strings.Contains(t, ".") && unicode.IsLower(rune(t[0])) {
+				diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelError, TargetID: s.ID(), Text: embedsUnexportedStruct + t})
+			}
+		}
+	}
+	return diagnostics
+}
+
+// checkSealedInterfaces returns a diagnostic for each interface defined in the package having an unexported method
+func checkSealedInterfaces(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for name, in := range p.c.Interfaces {
+		if _, ok := p.typeAliases[name]; !ok && in.Sealed {
+			diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelInfo, TargetID: in.ID(), Text: sealedInterface})
+		}
+	}
+	return diagnostics
+}
+
+// checkDeprecatedAPIs returns a diagnostic for each deprecated API whose deprecation notice suggests a replacement
+func checkDeprecatedAPIs(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for id, notice := range p.deprecations() {
+		if suggestsReplacement(notice) {
+			diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelInfo, TargetID: id, Text: deprecatedAPI + notice})
+		}
+	}
+	return diagnostics
+}
+
+// checkDeprecationReplacements returns a diagnostic for each deprecated API whose
+// deprecation notice doesn't suggest a replacement
+func checkDeprecationReplacements(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for id, notice := range p.deprecations() {
+		if !suggestsReplacement(notice) {
+			diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelWarning, TargetID: id, Text: deprecatedNoReplacement + notice})
+		}
+	}
+	return diagnostics
+}
+
+// suggestsReplacement returns true when a deprecation notice suggests a replacement, in a clause that doesn't
+// negate the suggestion as "do not use [Foo]" does
+func suggestsReplacement(notice string) bool {
+	for _, m := range replacementRgx.FindAllStringIndex(notice, -1) {
+		clause := notice[:m[0]]
+		if seps := clauseRgx.FindAllStringIndex(clause, -1); len(seps) > 0 {
+			clause = clause[seps[len(seps)-1][1]:]
+		}
+		if !negationRgx.MatchString(clause) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPlatformSpecific returns a diagnostic for each export that isn't available on all platforms
+func checkPlatformSpecific(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for id, a := range p.platformExports {
+		if a.partial {
+			diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelInfo, TargetID: id, Text: platformSpecific + a.on})
+		}
+	}
+	return diagnostics
+}
+
+// checkPlatformVariants returns a diagnostic for each export whose declaration differs by platform
+func checkPlatformVariants(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for id, a := range p.platformExports {
+		if a.differ {
+			diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelWarning, TargetID: id, Text: platformVariants + a.on})
+		}
+	}
+	return diagnostics
+}
+
+// checkSkippedPackages returns a diagnostic for each package other than the library package having files in the
+// package's directory, and for each package of the subdirectories having no library package for which p's
+// directory is the closest parent having one
+func checkSkippedPackages(m *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, s := range p.skippedPackages {
+		diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelWarning, TargetID: p.relName, Text: skippedPackage + s})
+	}
+	for _, dir := range sortedKeys(m.skippedDirs) {
+		if m.parentPackage(dir) != p {
+			continue
+		}
+		for _, s := range m.skippedDirs[dir] {
+			diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelWarning, TargetID: p.relName, Text: skippedPackage + s + " in " + strings.TrimPrefix(dir, p.relName+"/")})
+		}
+	}
+	return diagnostics
+}
+
+// parentPackage returns the package of the closest parent directory of dir having one, or nil when there's none
+func (m *Module) parentPackage(dir string) *Pkg {
+	var parent *Pkg
+	for _, p := range m.packages {
+		if strings.HasPrefix(dir, p.relName+"/") && (parent == nil || len(p.relName) > len(parent.relName)) {
+			parent = p
+		}
+	}
+	return parent
+}
@@ -0,0 +1,210 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countRules counts a review's diagnostics by rule ID
+func countRules(review PackageReview) map[string]int {
+	counts := map[string]int{}
+	for _, d := range review.Diagnostics {
+		counts[d.DiagnosticID]++
+	}
+	return counts
+}
+
+// ruleDiagnostics returns the texts of the warnings the given rule reports for the module in dir, keyed by
+// target ID and without the rule's prefix such as "Enum's possible values are incomplete or inconsistent: "
+func ruleDiagnostics(t *testing.T, dir, ruleID string) map[string][]string {
+	review, err := createReview(filepath.Clean(dir), Options{})
+	require.NoError(t, err)
+	diagnostics := map[string][]string{}
+	for _, d := range review.Diagnostics {
+		if d.DiagnosticID != ruleID {
+			continue
+		}
+		require.Equal(t, DiagnosticLevelWarning, d.Level)
+		_, text, ok := strings.Cut(d.Text, ": ")
+		require.True(t, ok, d.Text)
+		diagnostics[d.TargetID] = append(diagnostics[d.TargetID], text)
+	}
+	return diagnostics
+}
+
+func TestRules(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_deprecated"), Options{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"DeprecatedAPI": 3, "DeprecatedNoReplacement": 3}, countRules(review))
+	for _, d := range review.Diagnostics {
+		require.Equal(t, findRule(d.DiagnosticID).HelpLinkURI(), d.HelpLinkURI)
+	}
+
+	config := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(config, []byte(`{"rules": {"DeprecatedAPI": "off", "DeprecatedNoReplacement": "error"}}`), 0644))
+	review, err = createReview(filepath.Clean("testdata/test_deprecated"), Options{Rules: config})
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"DeprecatedNoReplacement": 3}, countRules(review))
+	for _, d := range review.Diagnostics {
+		require.Equal(t, DiagnosticLevelError, d.Level)
+	}
+
+	for _, content := range []string{
+		`{"rules": {"NoSuchRule": "off"}}`,
+		`{"rules": {"DeprecatedAPI": "fatal"}}`,
+		`not JSON`,
+	} {
+		require.NoError(t, os.WriteFile(config, []byte(content), 0644))
+		_, err = createReview(filepath.Clean("testdata/test_deprecated"), Options{Rules: config})
+		require.Error(t, err, content)
+	}
+}
+
+func TestSuggestsReplacement(t *testing.T) {
+	for notice, expected := range map[string]bool{
+		"use [Gadget] instead.":                      true,
+		"Run has been replaced by [Gadget.Run].":     true,
+		"prefer NewColor.":                           true,
+		"Use `NewClient` instead.":                   true,
+		"use azcore.NewClient.":                      true,
+		"Superseded by the Widgets type.":            true,
+		"Not thread safe; use [SafeWidget] instead.": true,
+		"this is no longer needed, use [Gadget].":    true,
+		"Deprecated: do not use.":                    false,
+		"don't use this":                             false,
+		"no longer used.":                            false,
+		"Do not use [Widget] in new code.":           false,
+		"you should never use Widget.":               false,
+		"it's inaccurate.":                           false,
+		"use something else.":                        false,
+	} {
+		require.Equal(t, expected, suggestsReplacement(notice), notice)
+	}
+}
+
+// exportedFuncs is a custom rule reporting every exported func
+type exportedFuncs struct{}
+
+func (exportedFuncs) ID() string          { return "ExportedFunc" }
+func (exportedFuncs) Description() string { return "Package exports a func" }
+func (exportedFuncs) HelpLinkURI() string { return "" }
+func (exportedFuncs) Check(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, f := range p.c.Funcs {
+		if f.Exported() && f.ReceiverType == "" {
+			diagnostics = append(diagnostics, Diagnostic{Level: DiagnosticLevelInfo, TargetID: f.ID(), Text: "exported func " + f.Name()})
+		}
+	}
+	return diagnostics
+}
+
+func TestRegisterRule(t *testing.T) {
+	registered := slices.Clone(rules)
+	t.Cleanup(func() { rules = registered })
+	require.NoError(t, RegisterRule(exportedFuncs{}))
+	require.Error(t, RegisterRule(exportedFuncs{}))
+	require.Error(t, RegisterRule(rule{}))
+
+	review, err := createReview(filepath.Clean("testdata/test_deprecated"), Options{})
+	require.NoError(t, err)
+	custom := []Diagnostic{}
+	for _, d := range review.Diagnostics {
+		if d.DiagnosticID == "ExportedFunc" {
+			custom = append(custom, d)
+		}
+	}
+	require.Equal(t, []Diagnostic{
+		{DiagnosticID: "ExportedFunc", Level: DiagnosticLevelInfo, TargetID: "test_deprecated-NotDeprecated", Text: "exported func NotDeprecated"},
+		{DiagnosticID: "ExportedFunc", Level: DiagnosticLevelInfo, TargetID: "test_deprecated-OldFunc", Text: "exported func OldFunc"},
+	}, custom)
+
+	log := newSARIFLog(review)
+	ruleIDs := []string{}
+	for _, r := range log.Runs[0].Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, r.ID)
+		require.Equal(t, findRule(r.ID).Description(), r.ShortDescription.Text)
+	}
+	require.Equal(t, []string{"DeprecatedAPI", "DeprecatedNoReplacement", "ExportedFunc"}, ruleIDs)
+}
+
+func TestClientConstructors(t *testing.T) {
+	require.Equal(t, map[string][]string{
+		"test_client_ctors.BadClient": {
+			"BadClientOptions doesn't embed azcore.ClientOptions",
+			"NewBadClient parameters should be (endpoint string, credential azcore.TokenCredential, options *BadClientOptions), not (string, *BadClientOptions)",
+			"NewBadClient should return (*BadClient, error), not (*BadClient)",
+			"NewBadClientWithNoCredential should take options *BadClientOptions as its last parameter",
+		},
+		"test_client_ctors.ExtraClient": {
+			"ExtraClientOptions doesn't exist",
+			"NewExtraClientWithRetries isn't NewExtraClient or an approved variant of it (NewExtraClientFromConnectionString, NewExtraClientWithKeyCredential, NewExtraClientWithNoCredential, NewExtraClientWithSharedKeyCredential)",
+		},
+		"test_client_ctors.MissingClient": {"has no NewMissingClient constructor or factory method"},
+		"test_client_ctors.OrphanClient":  {"has no NewOrphanClient constructor, and factory method NewOrphanClient has no doc comment"},
+	}, ruleDiagnostics(t, "testdata/test_client_ctors", "ClientConstructor"))
+}
+
+func TestClientMethods(t *testing.T) {
+	require.Equal(t, map[string][]string{
+		"test_client_methods-(c *WidgetClient) Delete": {
+			"Delete should return (WidgetClientDeleteResponse, error), not (error)",
+			"Delete should take options *WidgetClientDeleteOptions as its last parameter",
+			"WidgetClientDeleteOptions isn't defined",
+			"WidgetClientDeleteResponse isn't defined",
+		},
+		"test_client_methods-(c *WidgetClient) Update": {
+			"Update should return (WidgetClientUpdateResponse, error), not (*UpdateResponse, error)",
+			"Update should take options *WidgetClientUpdateOptions as its last parameter",
+			"WidgetClientUpdateOptions isn't defined",
+			"WidgetClientUpdateResponse isn't defined",
+		},
+	}, ruleDiagnostics(t, "testdata/test_client_methods", "ClientMethod"))
+	require.Equal(t, map[string][]string{
+		"test_client_methods.WidgetClientRenameOptions":  {"WidgetClientRenameOptions"},
+		"test_client_methods.WidgetClientRenameResponse": {"WidgetClientRenameResponse"},
+	}, ruleDiagnostics(t, "testdata/test_client_methods", "OrphanedOptionsResponse"))
+}
+
+func TestContextAndErrors(t *testing.T) {
+	require.Equal(t, map[string][]string{
+		"test_context_errors-(c *Client) Delete":        {"Delete should take ctx context.Context as its first parameter"},
+		"test_context_errors-(c *Client) Put":           {"Put should take ctx context.Context as its first parameter"},
+		"test_context_errors-Parse":                     {"Parse should return error last"},
+		"test_context_errors-Wait":                      {"Wait should take ctx context.Context as its first parameter"},
+		"test_context_errors-Watch":                     {"Watch should take ctx context.Context as its first parameter"},
+		"Ctx-test_context_errors.Request":               {"field Ctx shouldn't be a context.Context; pass contexts to methods instead"},
+		"test_context_errors-(c *RemoteClient) Reorder": {"Reorder should take ctx context.Context as its first parameter"},
+		"Parent-test_context_errors.RemoteClient":       {"field Parent shouldn't be a context.Context; pass contexts to methods instead"},
+		"test_context_errors.Operation":                 {"Operation shouldn't embed context.Context; pass contexts to methods instead"},
+	}, ruleDiagnostics(t, "testdata/test_context_errors", "ContextFirstErrorLast"))
+}
+
+func TestPagersPollers(t *testing.T) {
+	require.Equal(t, map[string][]string{
+		"test_pagers_pollers-(c *WidgetClient) NewListDeletedPager": {"NewListDeletedPager should return *runtime.Pager[WidgetClientListDeletedResponse], not (*runtime.Pager[WidgetClientListResponse])"},
+		"test_pagers_pollers-(c *WidgetClient) NewSearchPager":      {"NewSearchPager should return *runtime.Pager[WidgetClientSearchResponse], not (*runtime.Pager[WidgetClientSearchResponse], error)"},
+		"test_pagers_pollers-(c *WidgetClient) BeginDelete":         {"BeginDelete should return (*runtime.Poller[WidgetClientDeleteResponse], error), not (*runtime.Poller[WidgetClientDeleteResponse])"},
+		"test_pagers_pollers-(c *WidgetClient) ListAll":             {"ListAll returns a pager, so its name should be New<Operation>Pager"},
+		"test_pagers_pollers-(c *WidgetClient) Restore":             {"Restore returns a poller, so its name should be Begin<Operation>"},
+		"test_pagers_pollers-(c *GadgetClient) Reload":              {"Reload returns a poller, so its name should be Begin<Operation>"},
+	}, ruleDiagnostics(t, "testdata/test_pagers_pollers", "PagerPoller"))
+}
+
+func TestEnums(t *testing.T) {
+	require.Equal(t, map[string][]string{
+		"test_enums.ShapeRound":         {"ShapeRound has the same value as ShapeCircle"},
+		"test_enums.ShapeSquare":        {"PossibleShapeValues doesn't return ShapeSquare"},
+		"test_enums.ShapeStar":          {"PossibleShapeValues doesn't return ShapeStar"},
+		"test_enums.Size":               {"Size has no PossibleSizeValues func"},
+		"test_enums-PossibleTierValues": {"PossibleTierValues should be func() []Tier, not func() ([]string)"},
+		"test_enums.Unit":               {"Unit has PossibleUnitValues but no consts"},
+	}, ruleDiagnostics(t, "testdata/test_enums", "EnumValues"))
+}
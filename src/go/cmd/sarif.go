@@ -0,0 +1,157 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// This file contains a minimal model of the SARIF 2.1.0 format, which code scanning tools such as
+// GitHub's use to annotate pull requests. See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLevels maps diagnostic levels to SARIF result levels
+var sarifLevels = map[DiagnosticLevel]string{
+	DiagnosticLevelInfo:    "note",
+	DiagnosticLevelWarning: "warning",
+	DiagnosticLevelError:   "error",
+}
+
+// diagnosticRuleID returns the ID of the rule that produced a diagnostic
+func diagnosticRuleID(d Diagnostic) string {
+	if d.DiagnosticID != "" {
+		return d.DiagnosticID
+	}
+	return "Diagnostic"
+}
+
+// artifactURI returns a relative URI for a source file. Paths are relative to the working directory,
+// so running the tool from the root of a repository produces URIs code scanning tools can resolve.
+func artifactURI(filename string) string {
+	if filepath.IsAbs(filename) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, filename); err == nil && !strings.HasPrefix(rel, "..") {
+				filename = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(filename))
+}
+
+// newSARIFLog converts a review's diagnostics to a SARIF log. Results are located
+// at the declaration their diagnostic targets, when that has a known position.
+func newSARIFLog(review PackageReview) sarifLog {
+	rules := map[string]sarifRule{}
+	results := []sarifResult{}
+	for _, d := range review.Diagnostics {
+		id := diagnosticRuleID(d)
+		if _, ok := rules[id]; !ok {
+			rule := sarifRule{ID: id, HelpURI: d.HelpLinkURI}
+			if r := findRule(id); r != nil {
+				rule.ShortDescription.Text = r.Description()
+			}
+			if rule.ShortDescription.Text == "" {
+				rule.ShortDescription.Text = id
+			}
+			rules[id] = rule
+		}
+		result := sarifResult{RuleID: id, Level: sarifLevels[d.Level], Message: sarifMessage{Text: d.Text}}
+		if result.Level == "" {
+			result.Level = "none"
+		}
+		if pos, ok := review.positions[d.TargetID]; ok && pos.IsValid() {
+			result.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: artifactURI(pos.Filename)},
+				Region:           sarifRegion{StartLine: pos.Line, StartColumn: pos.Column},
+			}}}
+		}
+		results = append(results, result)
+	}
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	driver := sarifDriver{Name: "apiviewgo"}
+	for _, id := range ids {
+		driver.Rules = append(driver.Rules, rules[id])
+	}
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
+
+// writeSARIFFile writes a review's diagnostics to the named file in SARIF format
+func writeSARIFFile(filename string, review PackageReview) error {
+	b, err := json.MarshalIndent(newSARIFLog(review), "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, b, 0644)
+}
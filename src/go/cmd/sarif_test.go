@@ -0,0 +1,50 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSARIF(t *testing.T) {
+	review, err := createReview(filepath.Clean("testdata/test_deprecated"), Options{})
+	require.NoError(t, err)
+	review.Diagnostics = append(review.Diagnostics, Diagnostic{DiagnosticID: "Custom", Level: DiagnosticLevelError, TargetID: "test_deprecated", Text: "module problem"})
+	log := newSARIFLog(review)
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+
+	ruleIDs := []string{}
+	for _, r := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, r.ID)
+		require.NotEmpty(t, r.ShortDescription.Text)
+	}
+	require.Equal(t, []string{"Custom", "DeprecatedAPI", "DeprecatedNoReplacement"}, ruleIDs)
+
+	located := map[string]sarifRegion{}
+	for _, r := range run.Results {
+		if r.RuleID == "Custom" {
+			// the package has no source position
+			require.Equal(t, "error", r.Level)
+			require.Empty(t, r.Locations)
+			continue
+		}
+		require.Len(t, r.Locations, 1)
+		require.Equal(t, "testdata/test_deprecated/test.go", r.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		located[r.Message.Text] = r.Locations[0].PhysicalLocation.Region
+		if r.RuleID == "DeprecatedAPI" {
+			require.Equal(t, "note", r.Level)
+		} else {
+			require.Equal(t, "warning", r.Level)
+		}
+	}
+	// func, struct field and interface method positions
+	require.Equal(t, sarifRegion{StartLine: 49, StartColumn: 6}, located[deprecatedNoReplacement+"this paragraph continues."])
+	require.Equal(t, sarifRegion{StartLine: 13, StartColumn: 2}, located[deprecatedNoReplacement+"no longer used."])
+	require.Equal(t, sarifRegion{StartLine: 33, StartColumn: 2}, located[deprecatedNoReplacement+"it's inaccurate."])
+}
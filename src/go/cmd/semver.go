@@ -0,0 +1,171 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+var semverCmd = &cobra.Command{
+	Use:   "semver <baseModuleDir> <moduleDir>",
+	Short: "Determines the version bump a module's API changes require",
+	Long: `semver compares a module to a base version of itself and reports whether its API
+changes require a major, minor or patch version bump. It fails when the module breaks
+the API of the base version without changing its major version suffix, or when the
+version declared by the module's moduleVersion constant doesn't match the changes.`,
+	Args:          cobra.ExactArgs(2),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		base, err := NewModule(args[0], Options{})
+		if err != nil {
+			return err
+		}
+		m, err := NewModule(args[1], Options{})
+		if err != nil {
+			return err
+		}
+		report := checkVersion(base, m, diffAPIs(base, m))
+		fmt.Printf("Required version bump: %s\n", report.Bump)
+		fmt.Printf("Base: %s %s\n", report.BasePath, report.BaseVersion)
+		fmt.Printf("Module: %s %s\n", report.Path, report.Version)
+		failed := false
+		for _, d := range report.Diagnostics {
+			fmt.Printf("%s: %s\n", levelNames[d.Level], d.Text)
+			failed = failed || d.Level == DiagnosticLevelError
+		}
+		if failed {
+			return errors.New("module version doesn't follow semantic import versioning")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(semverCmd)
+}
+
+// levelNames maps diagnostic levels to their names for console output
+var levelNames = map[DiagnosticLevel]string{
+	DiagnosticLevelInfo:    "info",
+	DiagnosticLevelWarning: "warning",
+	DiagnosticLevelError:   "error",
+}
+
+// VersionBump is the version increment required by an API change
+type VersionBump string
+
+const (
+	VersionBumpMajor VersionBump = "major"
+	VersionBumpMinor VersionBump = "minor"
+	VersionBumpPatch VersionBump = "patch"
+)
+
+// versionBumpRank orders version bumps by significance
+var versionBumpRank = map[VersionBump]int{VersionBumpPatch: 0, VersionBumpMinor: 1, VersionBumpMajor: 2}
+
+// versionConst is the name of the constant in which Azure SDK modules declare their version
+const versionConst = "moduleVersion"
+
+// VersionReport describes the version bump an API change requires and any problems with a module's version
+type VersionReport struct {
+	BasePath    string
+	BaseVersion string
+	// Bump is the version bump the module's API changes require
+	Bump        VersionBump
+	Diagnostics []Diagnostic
+	Path        string
+	Version     string
+}
+
+// checkVersion determines the version bump required by the changes from base to m and
+// checks m's module path and declared version against it
+func checkVersion(base, m *Module, d APIDiff) VersionReport {
+	r := VersionReport{
+		BasePath:    base.path,
+		BaseVersion: moduleVersion(base),
+		Bump:        VersionBumpPatch,
+		Path:        m.path,
+		Version:     moduleVersion(m),
+	}
+	if len(d.Breaking()) > 0 {
+		r.Bump = VersionBumpMajor
+	} else if len(d.Changes) > 0 {
+		r.Bump = VersionBumpMinor
+	}
+	// breaking changes are allowed in minor versions of a v0 module
+	if r.Bump == VersionBumpMajor && semver.Major(r.BaseVersion) == "v0" {
+		r.Bump = VersionBumpMinor
+	}
+
+	target := baseModuleName(m.path)
+	report := func(level DiagnosticLevel, format string, args ...any) {
+		r.Diagnostics = append(r.Diagnostics, Diagnostic{Level: level, TargetID: target, Text: fmt.Sprintf(format, args...)})
+	}
+
+	baseSuffix, suffix := majorVerSuffix.FindString(base.path), majorVerSuffix.FindString(m.path)
+	if r.Bump == VersionBumpMajor && baseSuffix == suffix {
+		report(DiagnosticLevelError, "%d breaking change(s) require a new major version but the module path %q has the same major version suffix as %q", len(d.Breaking()), m.path, base.path)
+	} else if r.Bump != VersionBumpMajor && baseSuffix != suffix && semver.Major(r.BaseVersion) != "v0" {
+		report(DiagnosticLevelWarning, "module path %q changes the major version suffix of %q without breaking changes", m.path, base.path)
+	}
+
+	if r.Version == "" {
+		return r
+	}
+	if !semver.IsValid(r.Version) {
+		report(DiagnosticLevelError, "%s %q isn't a valid semantic version", versionConst, r.Version)
+		return r
+	}
+	// only v2 and later have a major version suffix
+	wantSuffix := ""
+	if major := semver.Major(r.Version); major != "v0" && major != "v1" {
+		wantSuffix = "/" + major
+	}
+	if suffix != wantSuffix {
+		report(DiagnosticLevelError, "%s %s doesn't match the major version of module path %q", versionConst, r.Version, m.path)
+	}
+	if r.BaseVersion == "" || !semver.IsValid(r.BaseVersion) {
+		return r
+	}
+	if semver.Compare(r.Version, r.BaseVersion) <= 0 {
+		report(DiagnosticLevelError, "%s %s must be greater than base version %s", versionConst, r.Version, r.BaseVersion)
+		return r
+	}
+	declared := VersionBumpPatch
+	if semver.Major(r.Version) != semver.Major(r.BaseVersion) {
+		declared = VersionBumpMajor
+	} else if semver.MajorMinor(r.Version) != semver.MajorMinor(r.BaseVersion) {
+		declared = VersionBumpMinor
+	}
+	if versionBumpRank[declared] < versionBumpRank[r.Bump] {
+		report(DiagnosticLevelError, "API changes require a %s version bump but %s %s is a %s bump from %s", r.Bump, versionConst, r.Version, declared, r.BaseVersion)
+	}
+	return r
+}
+
+// moduleVersion returns the version a module declares in its moduleVersion constant, or "" when there is no such constant
+func moduleVersion(m *Module) string {
+	// search packages in a consistent order in case several declare the constant
+	names := make([]string, 0, len(m.packages))
+	for name := range m.packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if decl, ok := m.packages[name].c.Consts[versionConst]; ok {
+			if v, err := strconv.Unquote(decl.value); err == nil {
+				return strings.TrimSpace(v)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckVersion(t *testing.T) {
+	load := func(dir string) *Module {
+		m, err := NewModule(filepath.Clean("testdata/test_diff/"+dir+"/widgets"), Options{})
+		require.NoError(t, err)
+		return m
+	}
+	old, v1, v2 := load("old"), load("new"), load("v2")
+
+	t.Run("breaking without new major version", func(t *testing.T) {
+		r := checkVersion(old, v1, diffAPIs(old, v1))
+		require.Equal(t, VersionBumpMajor, r.Bump)
+		require.Equal(t, "v1.0.0", r.BaseVersion)
+		require.Equal(t, "v1.1.0", r.Version)
+		require.Len(t, r.Diagnostics, 2)
+		for _, d := range r.Diagnostics {
+			require.Equal(t, DiagnosticLevelError, d.Level)
+			require.Equal(t, "widgets", d.TargetID)
+		}
+		require.Contains(t, r.Diagnostics[0].Text, "7 breaking change(s) require a new major version")
+		require.Contains(t, r.Diagnostics[1].Text, "moduleVersion v1.1.0 is a minor bump from v1.0.0")
+	})
+
+	t.Run("breaking with new major version", func(t *testing.T) {
+		r := checkVersion(old, v2, diffAPIs(old, v2))
+		require.Equal(t, VersionBumpMajor, r.Bump)
+		require.Equal(t, "v2.0.0", r.Version)
+		require.Empty(t, r.Diagnostics)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		r := checkVersion(v1, v1, diffAPIs(v1, v1))
+		require.Equal(t, VersionBumpPatch, r.Bump)
+		require.Len(t, r.Diagnostics, 1)
+		require.Contains(t, r.Diagnostics[0].Text, "must be greater than base version")
+	})
+
+	t.Run("new major version without breaking changes", func(t *testing.T) {
+		r := checkVersion(v1, v2, diffAPIs(v1, v2))
+		require.Equal(t, VersionBumpPatch, r.Bump)
+		require.Len(t, r.Diagnostics, 1)
+		require.Equal(t, DiagnosticLevelWarning, r.Diagnostics[0].Level)
+	})
+}
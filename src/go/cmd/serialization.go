@@ -0,0 +1,173 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// wireFormats maps the tag keys of serialization formats to the methods implementing custom marshaling for them
+var wireFormats = map[string][]string{
+	"json": {"MarshalJSON", "UnmarshalJSON"},
+	"xml":  {"MarshalXML", "UnmarshalXML"},
+}
+
+// serializedStruct is the serialization metadata of an exported struct. Structs having json tags are models
+// whose wire names are part of their API.
+type serializedStruct struct {
+	s Struct
+	// fields are the names of the struct's exported fields, sorted. Encoders ignore unexported fields.
+	fields []string
+	// marshalers maps formats to the struct's methods implementing custom marshaling for them, if any
+	marshalers map[string][]string
+	// model is true when any of the struct's exported fields has a json tag
+	model bool
+	tags  map[string]reflect.StructTag
+}
+
+// serializedStructs returns the serialization metadata of the package's exported structs
+func (p *Pkg) serializedStructs() []serializedStruct {
+	structs := []serializedStruct{}
+	for _, name := range sortedKeys(p.c.Structs) {
+		s := p.c.Structs[name]
+		if !s.Exported() {
+			continue
+		}
+		ss := serializedStruct{s: s, marshalers: map[string][]string{}, tags: map[string]reflect.StructTag{}}
+		methods := map[string]bool{}
+		for _, m := range p.c.findMethods(s.Name()) {
+			methods[m.Name()] = true
+		}
+		for format, marshalers := range wireFormats {
+			for _, m := range marshalers {
+				if methods[m] {
+					ss.marshalers[format] = append(ss.marshalers[format], m)
+				}
+			}
+		}
+		for field := range s.fields {
+			if !unicode.IsUpper(rune(field[0])) {
+				continue
+			}
+			ss.fields = append(ss.fields, field)
+			if lit, ok := s.fieldTags[field]; ok {
+				if tag, err := strconv.Unquote(lit); err == nil {
+					ss.tags[field] = reflect.StructTag(tag)
+				}
+			}
+			if _, ok := ss.tags[field].Lookup("json"); ok {
+				ss.model = true
+			}
+		}
+		sort.Strings(ss.fields)
+		structs = append(structs, ss)
+	}
+	return structs
+}
+
+// customMarshaling returns true when the struct has methods implementing both marshaling and unmarshaling
+// of the format, so that encoders ignore the format's tags in both directions
+func (ss serializedStruct) customMarshaling(format string) bool {
+	return len(ss.marshalers[format]) == len(wireFormats[format])
+}
+
+// checkMissingJSONTags returns a diagnostic for each exported field of a model lacking a json tag, unless the
+// model has custom JSON marshaling and unmarshaling
+func checkMissingJSONTags(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, ss := range p.serializedStructs() {
+		if !ss.model || ss.customMarshaling("json") {
+			continue
+		}
+		for _, field := range ss.fields {
+			if _, ok := ss.tags[field].Lookup("json"); !ok {
+				diagnostics = append(diagnostics, Diagnostic{
+					Level:    DiagnosticLevelWarning,
+					TargetID: field + "-" + ss.s.ID(),
+					Text:     missingJSONTag + field,
+				})
+			}
+		}
+	}
+	return diagnostics
+}
+
+// checkIgnoredTags returns a diagnostic for each tag of a format for which the field's struct has custom marshaling.
+// When the struct customizes only marshaling or only unmarshaling, the diagnostic says which direction ignores the tag.
+func checkIgnoredTags(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, ss := range p.serializedStructs() {
+		for _, field := range ss.fields {
+			for _, format := range sortedKeys(wireFormats) {
+				methods := ss.marshalers[format]
+				if _, ok := ss.tags[field].Lookup(format); !ok || len(methods) == 0 {
+					continue
+				}
+				text := fmt.Sprintf("%s%s tag of %s (%s has methods %s)", ignoredTag, format, field, ss.s.Name(), strings.Join(methods, " and "))
+				if !ss.customMarshaling(format) {
+					direction := "marshaling"
+					if strings.HasPrefix(methods[0], "Unmarshal") {
+						direction = "unmarshaling"
+					}
+					text = fmt.Sprintf("%s%s tag of %s when %s (%s has method %s)", ignoredTag, format, field, direction, ss.s.Name(), methods[0])
+				}
+				diagnostics = append(diagnostics, Diagnostic{
+					Level:    DiagnosticLevelWarning,
+					TargetID: field + "-" + ss.s.ID(),
+					Text:     text,
+				})
+			}
+		}
+	}
+	return diagnostics
+}
+
+// checkConflictingWireNames returns a diagnostic for each JSON or XML name shared by several fields of a struct
+func checkConflictingWireNames(_ *Module, p *Pkg) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, ss := range p.serializedStructs() {
+		for _, format := range sortedKeys(wireFormats) {
+			if ss.customMarshaling(format) {
+				continue
+			}
+			// wireNames maps wire names to the fields having them
+			wireNames := map[string][]string{}
+			for _, field := range ss.fields {
+				value, ok := ss.tags[field].Lookup(format)
+				if !ok || value == "-" {
+					// the field has no tag of this format, or isn't serialized
+					continue
+				}
+				name, _, _ := strings.Cut(value, ",")
+				if name == "" {
+					name = field
+				}
+				wireNames[name] = append(wireNames[name], field)
+			}
+			for _, name := range sortedKeys(wireNames) {
+				if fields := wireNames[name]; len(fields) > 1 {
+					diagnostics = append(diagnostics, Diagnostic{
+						Level:    DiagnosticLevelError,
+						TargetID: ss.s.ID(),
+						Text:     fmt.Sprintf("%s%s %q (%s)", conflictingWireNames, format, name, strings.Join(fields, ", ")),
+					})
+				}
+			}
+		}
+	}
+	return diagnostics
+}
+
+// showStructTags includes field tags in the tokens of the content's structs
+func (c *content) showStructTags() {
+	for name, s := range c.Structs {
+		s.showTags = true
+		c.Structs[name] = s
+	}
+}
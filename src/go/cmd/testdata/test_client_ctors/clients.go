@@ -0,0 +1,87 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_client_ctors
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+)
+
+// GoodClient follows the constructor conventions
+type GoodClient struct{}
+
+// GoodClientOptions contains optional settings for GoodClient
+type GoodClientOptions struct {
+	azcore.ClientOptions
+}
+
+// NewGoodClient creates a GoodClient
+func NewGoodClient(endpoint string, credential azcore.TokenCredential, options *GoodClientOptions) (*GoodClient, error) {
+	return &GoodClient{}, nil
+}
+
+// NewGoodClientFromConnectionString creates a GoodClient from a connection string
+func NewGoodClientFromConnectionString(connectionString string, options *GoodClientOptions) (*GoodClient, error) {
+	return &GoodClient{}, nil
+}
+
+// NewSubClient creates a SubClient, which has no constructor of its own
+func (c *GoodClient) NewSubClient() *SubClient {
+	return &SubClient{}
+}
+
+func (c *GoodClient) NewOrphanClient() *OrphanClient {
+	return &OrphanClient{}
+}
+
+// SubClient is created by GoodClient
+type SubClient struct{}
+
+// OrphanClient is created by an undocumented factory method
+type OrphanClient struct{}
+
+// ManagementClient is an ARM client
+type ManagementClient struct{}
+
+// NewManagementClient creates a ManagementClient
+func NewManagementClient(subscriptionID string, credential azcore.TokenCredential, options *arm.ClientOptions) (*ManagementClient, error) {
+	return &ManagementClient{}, nil
+}
+
+// BadClient has a constructor with the wrong parameters and results
+type BadClient struct{}
+
+// BadClientOptions doesn't embed azcore.ClientOptions
+type BadClientOptions struct {
+	Retries int
+}
+
+// NewBadClient takes no credential
+func NewBadClient(endpoint string, options *BadClientOptions) *BadClient {
+	return &BadClient{}
+}
+
+// NewBadClientWithNoCredential takes options of the wrong type
+func NewBadClientWithNoCredential(endpoint string, options *GoodClientOptions) (*BadClient, error) {
+	return &BadClient{}, nil
+}
+
+// ExtraClient has an unapproved constructor and no options
+type ExtraClient struct{}
+
+// NewExtraClient creates an ExtraClient
+func NewExtraClient(endpoint string, credential azcore.TokenCredential, options *ExtraClientOptions) (*ExtraClient, error) {
+	return &ExtraClient{}, nil
+}
+
+// NewExtraClientWithRetries isn't an approved variant
+func NewExtraClientWithRetries(endpoint string, retries int) (*ExtraClient, error) {
+	return &ExtraClient{}, nil
+}
+
+// MissingClient has no constructor
+type MissingClient struct{}
+
+// internalClient isn't exported
+type internalClient struct{}
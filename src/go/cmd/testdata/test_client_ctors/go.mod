@@ -0,0 +1,3 @@
+module test_client_ctors
+
+go 1.21
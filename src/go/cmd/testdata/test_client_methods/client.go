@@ -0,0 +1,84 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_client_methods
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// WidgetClient manages widgets
+type WidgetClient struct{}
+
+// WidgetClientOptions contains optional settings for WidgetClient
+type WidgetClientOptions struct {
+	azcore.ClientOptions
+}
+
+// NewWidgetClient creates a WidgetClient
+func NewWidgetClient(endpoint string, credential azcore.TokenCredential, options *WidgetClientOptions) (*WidgetClient, error) {
+	return &WidgetClient{}, nil
+}
+
+// Get follows the conventions
+func (c *WidgetClient) Get(ctx context.Context, name string, options *WidgetClientGetOptions) (WidgetClientGetResponse, error) {
+	return WidgetClientGetResponse{}, nil
+}
+
+// NewListPager follows the conventions
+func (c *WidgetClient) NewListPager(options *WidgetClientListOptions) *runtime.Pager[WidgetClientListResponse] {
+	return nil
+}
+
+// BeginCreate follows the conventions
+func (c *WidgetClient) BeginCreate(ctx context.Context, options *WidgetClientBeginCreateOptions) (*runtime.Poller[WidgetClientCreateResponse], error) {
+	return nil, nil
+}
+
+// Delete has no options and returns only an error
+func (c *WidgetClient) Delete(ctx context.Context, name string) error {
+	return nil
+}
+
+// Update has options and a response of the wrong types, which aren't defined
+func (c *WidgetClient) Update(ctx context.Context, opts *UpdateOptions) (*UpdateResponse, error) {
+	return nil, nil
+}
+
+// Endpoint isn't an operation
+func (c *WidgetClient) Endpoint() string {
+	return ""
+}
+
+// WidgetClientGetOptions contains the optional parameters for WidgetClient.Get
+type WidgetClientGetOptions struct{}
+
+// WidgetClientGetResponse contains the response from WidgetClient.Get
+type WidgetClientGetResponse struct{}
+
+// WidgetClientListOptions contains the optional parameters for WidgetClient.NewListPager
+type WidgetClientListOptions struct{}
+
+// WidgetClientListResponse contains the response from WidgetClient.NewListPager
+type WidgetClientListResponse struct{}
+
+// WidgetClientBeginCreateOptions contains the optional parameters for WidgetClient.BeginCreate
+type WidgetClientBeginCreateOptions struct{}
+
+// WidgetClientCreateResponse contains the response from WidgetClient.BeginCreate
+type WidgetClientCreateResponse struct{}
+
+// WidgetClientRenameOptions is left over from a removed method
+type WidgetClientRenameOptions struct{}
+
+// WidgetClientRenameResponse is left over from a removed method
+type WidgetClientRenameResponse struct{}
+
+// UpdateOptions isn't named for its client and operation
+type UpdateOptions struct{}
+
+// UpdateResponse isn't named for its client and operation
+type UpdateResponse struct{}
@@ -0,0 +1,3 @@
+module test_client_methods
+
+go 1.21
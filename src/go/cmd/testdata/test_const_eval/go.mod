@@ -0,0 +1,3 @@
+module test_const_eval
+
+go 1.21
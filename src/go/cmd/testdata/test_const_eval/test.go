@@ -0,0 +1,50 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package testconsteval
+
+import (
+	"math"
+	"unsafe"
+)
+
+type Size int
+
+type Flags Bits
+
+type Bits uint8
+
+const (
+	B Size = 1 << (10 * iota)
+	KB
+	MB
+)
+
+const (
+	First, Second = iota, iota + 10
+	Third, Fourth
+)
+
+const (
+	Agent   = "foo/" + Version
+	Version = "0.1.0"
+)
+
+const (
+	Mask      = ^uint8(0) >> 1
+	Greeting  = "hello, " + "world"
+	Enabled   = KB > B && !false
+	Quarter   = 1.0 / 4
+	Letter    = string(rune(65))
+	Remote    = math.MaxInt8
+	Hex       = 0x10
+	Formatted = `raw`
+)
+
+const (
+	Complex  = 1 + 2i
+	RealPart = real(Complex)
+	ImagPart = imag(Complex)
+	Width    = unsafe.Sizeof(Hex)
+	AllFlags = ^Flags(0)
+)
@@ -0,0 +1,14 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_context_errors
+
+import gocontext "context"
+
+// Watch takes an aliased context other than first
+func Watch(name string, ctx gocontext.Context) {}
+
+// Stream holds an aliased context
+type Stream struct {
+	ctx gocontext.Context
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_context_errors
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// Client performs I/O
+type Client struct{}
+
+// Get takes a context first
+func (c *Client) Get(ctx context.Context, name string) error {
+	return nil
+}
+
+// Delete performs I/O without a context
+func (c *Client) Delete(name string) error {
+	return nil
+}
+
+// Put takes a context other than first
+func (c *Client) Put(name string, ctx context.Context) error {
+	return nil
+}
+
+// NewListPager returns a pager, whose methods take a context
+func (c *Client) NewListPager() *runtime.Pager[string] {
+	return nil
+}
+
+// NewSubClient is a factory method
+func (c *Client) NewSubClient() (*SubClient, error) {
+	return nil, nil
+}
+
+// Credential doesn't perform I/O
+func (c *Client) Credential() azcore.TokenCredential {
+	return nil
+}
+
+// SubClient is created by Client
+type SubClient struct{}
+
+// Parse returns an error other than last
+func Parse(s string) (error, int) {
+	return nil, 0
+}
+
+// Wait takes a context other than first
+func Wait(name string, ctx context.Context) {}
+
+// Request holds contexts
+type Request struct {
+	Ctx  context.Context
+	Name string
+	ctx  context.Context
+}
+
+// Operation embeds a context
+type Operation struct {
+	context.Context
+}
@@ -0,0 +1,3 @@
+module test_context_errors
+
+go 1.21
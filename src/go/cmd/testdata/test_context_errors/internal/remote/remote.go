@@ -0,0 +1,20 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package remote
+
+import ctxpkg "context"
+
+// RemoteClient is exported by alias, and imports context under another name than the aliasing file
+type RemoteClient struct {
+	// Parent is a context
+	Parent ctxpkg.Context
+}
+
+// Fetch takes a context first
+func (c *RemoteClient) Fetch(ctx ctxpkg.Context, name string) error {
+	return nil
+}
+
+// Reorder takes a context other than first
+func (c *RemoteClient) Reorder(name string, ctx ctxpkg.Context) {}
@@ -0,0 +1,9 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_context_errors
+
+import "test_context_errors/internal/remote"
+
+// RemoteClient is defined in another package
+type RemoteClient = remote.RemoteClient
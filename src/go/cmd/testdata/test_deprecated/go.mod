@@ -0,0 +1,3 @@
+module test_deprecated
+
+go 1.18
@@ -0,0 +1,52 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package testdeprecated
+
+// Widget is deprecated.
+//
+// Deprecated: use [Gadget] instead.
+type Widget struct {
+	// Size is deprecated.
+	//
+	// Deprecated: no longer used.
+	Size int
+
+	Name string
+}
+
+// Gadget replaces Widget.
+type Gadget struct{}
+
+// Run is deprecated.
+//
+// Deprecated: Run has been replaced by [Gadget.Run].
+func (w *Widget) Run() {}
+
+func (g *Gadget) Run() {}
+
+// Shape is an interface.
+type Shape interface {
+	// Area is deprecated.
+	//
+	// Deprecated: it's inaccurate.
+	Area() float64
+}
+
+const (
+	// OldColor is deprecated.
+	//
+	// Deprecated: prefer NewColor.
+	OldColor = "old"
+	NewColor = "new"
+)
+
+// OldFunc is deprecated.
+//
+// Deprecated:
+// this paragraph
+// continues.
+func OldFunc() {}
+
+// NotDeprecated mentions Deprecated: in the middle of a paragraph.
+func NotDeprecated() {}
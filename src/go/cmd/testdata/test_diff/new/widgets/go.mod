@@ -0,0 +1,3 @@
+module github.com/Azure/azure-sdk-for-go/sdk/widgets
+
+go 1.18
@@ -0,0 +1,3 @@
+package widgets
+
+const moduleVersion = "v1.1.0"
@@ -0,0 +1,50 @@
+package widgets
+
+import "context"
+
+type Client struct{}
+
+type ClientOptions struct {
+	Retries int
+}
+
+type BazOptions struct {
+	Count *int32
+	Name  string
+	Bar   bool
+}
+
+type Shape interface {
+	Area() float64
+	Perimeter() float64
+}
+
+type Color string
+
+const (
+	ColorRed   Color = "red"
+	ColorBlue  Color = "navy"
+	ColorGreen Color = "green"
+)
+
+// MaxSize is the maximum size.
+//
+// Deprecated: use Client options instead.
+const MaxSize = 10
+
+var DefaultColor Color
+
+func NewClient(endpoint string, options *ClientOptions) (*Client, error) {
+	return nil, nil
+}
+
+// receiver names don't matter
+func (cl *Client) Get(ctx context.Context, name string, version int) error {
+	return nil
+}
+
+func NewFooClient() *Client {
+	return nil
+}
+
+type Mode string
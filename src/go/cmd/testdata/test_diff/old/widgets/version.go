@@ -0,0 +1,3 @@
+package widgets
+
+const moduleVersion = "v1.0.0"
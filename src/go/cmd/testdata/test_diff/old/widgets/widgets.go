@@ -0,0 +1,45 @@
+package widgets
+
+import "context"
+
+type Client struct{}
+
+type ClientOptions struct {
+	Retries int
+}
+
+type BazOptions struct {
+	Count *string
+	Name  string
+}
+
+type Removed struct{}
+
+type Shape interface {
+	Area() float64
+}
+
+type Color string
+
+const (
+	ColorRed  Color = "red"
+	ColorBlue Color = "blue"
+)
+
+const MaxSize = 10
+
+var DefaultColor Color
+
+func NewClient(endpoint string, options *ClientOptions) (*Client, error) {
+	return nil, nil
+}
+
+func (c *Client) Get(ctx context.Context, name string) error {
+	return nil
+}
+
+func (c *Client) Delete(ctx context.Context) error {
+	return nil
+}
+
+type Mode int
@@ -0,0 +1,3 @@
+package widgets
+
+const moduleVersion = "v2.0.0"
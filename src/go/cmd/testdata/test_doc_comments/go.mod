@@ -0,0 +1,3 @@
+module test_doc_comments
+
+go 1.18
@@ -0,0 +1,44 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package testdoccomments is for testing doc comments.
+package testdoccomments
+
+// Widget is a struct.
+//
+// It has two paragraphs.
+type Widget struct {
+	// Name is a field.
+	Name string
+
+	Count int
+}
+
+// NewWidget creates a Widget.
+func NewWidget() *Widget {
+	return nil
+}
+
+// Resize is a method.
+func (w *Widget) Resize(size int) {}
+
+// Shape is an interface.
+type Shape interface {
+	// Area is an interface method.
+	Area() float64
+}
+
+// Color is a simple type.
+type Color string
+
+const (
+	// ColorRed is red.
+	ColorRed  Color = "red"
+	ColorBlue Color = "blue"
+)
+
+// Version is an ungrouped const.
+const Version = "1.0.0"
+
+// DefaultWidget is a var.
+var DefaultWidget *Widget
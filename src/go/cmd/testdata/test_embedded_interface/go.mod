@@ -0,0 +1,3 @@
+module test_embedded_interface
+
+go 1.21
@@ -0,0 +1,12 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_embedded_interface
+
+import "io"
+
+// ReadCloser only embeds other interfaces
+type ReadCloser interface {
+	io.Closer
+	io.Reader
+}
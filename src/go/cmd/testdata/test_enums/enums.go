@@ -0,0 +1,87 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_enums
+
+import "github.com/Azure/azure-sdk-for-go/sdk/azcore"
+
+// Client uses azcore
+type Client struct {
+	cred azcore.TokenCredential
+}
+
+// Color is a complete enum
+type Color string
+
+const (
+	ColorBlue Color = "blue"
+	ColorRed  Color = "red"
+)
+
+// PossibleColorValues returns the possible values for the Color const type.
+func PossibleColorValues() []Color {
+	return []Color{
+		ColorBlue,
+		ColorRed,
+	}
+}
+
+// Shape's Possible func doesn't return all its consts
+type Shape string
+
+const (
+	ShapeCircle Shape = "circle"
+	ShapeSquare Shape = "square"
+	// ShapeRound duplicates ShapeCircle
+	ShapeRound Shape = "circle"
+	// ShapeStar is typed by conversion
+	ShapeStar = Shape("star")
+)
+
+// PossibleShapeValues returns the possible values for the Shape const type.
+func PossibleShapeValues() []Shape {
+	return []Shape{
+		ShapeCircle,
+		ShapeRound,
+	}
+}
+
+// Size has no Possible func
+type Size string
+
+const (
+	SizeLarge Size = "large"
+	SizeSmall Size = "small"
+)
+
+// Tier's Possible func has the wrong signature
+type Tier string
+
+const TierFree Tier = "free"
+
+// PossibleTierValues returns the possible values for the Tier const type.
+func PossibleTierValues() []string {
+	return []string{string(TierFree)}
+}
+
+// Unit has a Possible func but no consts
+type Unit string
+
+// PossibleUnitValues returns the possible values for the Unit const type.
+func PossibleUnitValues() []Unit {
+	return nil
+}
+
+// Weight's Possible func can't be analyzed
+type Weight string
+
+const WeightHeavy Weight = "heavy"
+
+// PossibleWeightValues returns the possible values for the Weight const type.
+func PossibleWeightValues() []Weight {
+	values := []Weight{WeightHeavy}
+	return values
+}
+
+// Name is a string type without consts, which isn't an enum
+type Name string
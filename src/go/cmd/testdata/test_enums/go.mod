@@ -0,0 +1,3 @@
+module test_enums
+
+go 1.21
@@ -0,0 +1,3 @@
+module test_generics
+
+go 1.21
@@ -0,0 +1,56 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_generics
+
+// Number is a constraint permitting integer and float types
+type Number interface {
+	~int | ~int64 | float64
+}
+
+// Ordered is a constraint combining a type set with a method
+type Ordered interface {
+	~int | ~string
+	String() string
+}
+
+// Container holds values of type T
+type Container[T any] interface {
+	Get() T
+	Put(T)
+}
+
+// Widget is a type defined in this package
+type Widget struct{}
+
+// List is a generic defined type
+type List[T any] []T
+
+// Len returns the list's length
+func (l List[T]) Len() int {
+	return len(l)
+}
+
+// Map is a generic defined type having two type parameters
+type Map[K comparable, V Number] map[K]V
+
+// Set returns a copy of m having v at k
+func (m Map[K, V]) Set(k K, v V) Map[K, V] {
+	return m
+}
+
+// Pair is a generic struct
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+	W     *Widget
+}
+
+// Sum returns the sum of its arguments
+func Sum[S ~[]E, E Number](s S) E {
+	var sum E
+	for _, v := range s {
+		sum += v
+	}
+	return sum
+}
@@ -0,0 +1,24 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_ordering
+
+// Color is an enum
+type Color string
+
+const (
+	Red  Color = "red"
+	Blue Color = "blue"
+)
+
+// PossibleColorValues returns the possible values of Color
+func PossibleColorValues() []Color {
+	return []Color{Red, Blue}
+}
+
+const MaxWidgets = 10
+
+// Painter paints widgets
+type Painter interface {
+	Paint(*Widget)
+}
@@ -0,0 +1,23 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_ordering
+
+// Widget is declared before Color in this file but after it alphabetically
+type Widget struct {
+	Color Color
+}
+
+func (w *Widget) Zoom() {}
+
+func (w *Widget) Paint(c Color) {}
+
+// NewWidget is a constructor of Widget
+func NewWidget() *Widget {
+	return &Widget{}
+}
+
+var DefaultWidget = Widget{}
+
+// Build is a func that isn't grouped with any type
+func Build() {}
@@ -0,0 +1,3 @@
+module test_ordering
+
+go 1.21
@@ -0,0 +1,81 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_pagers_pollers
+
+import (
+	"context"
+
+	azruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// WidgetClient manages widgets
+type WidgetClient struct{}
+
+// NewListPager returns a pager of the right response
+func (c *WidgetClient) NewListPager(options *WidgetClientListOptions) *azruntime.Pager[WidgetClientListResponse] {
+	return nil
+}
+
+// NewListDeletedPager returns a pager of another operation's response
+func (c *WidgetClient) NewListDeletedPager(options *WidgetClientListDeletedOptions) *azruntime.Pager[WidgetClientListResponse] {
+	return nil
+}
+
+// NewSearchPager returns a pager and an error
+func (c *WidgetClient) NewSearchPager(options *WidgetClientSearchOptions) (*azruntime.Pager[WidgetClientSearchResponse], error) {
+	return nil, nil
+}
+
+// BeginCreate returns a poller of the right response
+func (c *WidgetClient) BeginCreate(ctx context.Context, options *WidgetClientBeginCreateOptions) (*azruntime.Poller[WidgetClientCreateResponse], error) {
+	return nil, nil
+}
+
+// BeginDelete returns no error
+func (c *WidgetClient) BeginDelete(ctx context.Context, options *WidgetClientBeginDeleteOptions) *azruntime.Poller[WidgetClientDeleteResponse] {
+	return nil
+}
+
+// ListAll returns a pager but isn't named like a pager method
+func (c *WidgetClient) ListAll(options *WidgetClientListOptions) *azruntime.Pager[WidgetClientListResponse] {
+	return nil
+}
+
+// Restore returns a poller but isn't named like a long-running operation
+func (c *WidgetClient) Restore(ctx context.Context, options *WidgetClientRestoreOptions) (*azruntime.Poller[WidgetClientRestoreResponse], error) {
+	return nil, nil
+}
+
+// WidgetClientListOptions contains the optional parameters for NewListPager
+type WidgetClientListOptions struct{}
+
+// WidgetClientListResponse is the response of NewListPager
+type WidgetClientListResponse struct{}
+
+// WidgetClientListDeletedOptions contains the optional parameters for NewListDeletedPager
+type WidgetClientListDeletedOptions struct{}
+
+// WidgetClientSearchOptions contains the optional parameters for NewSearchPager
+type WidgetClientSearchOptions struct{}
+
+// WidgetClientSearchResponse is the response of NewSearchPager
+type WidgetClientSearchResponse struct{}
+
+// WidgetClientBeginCreateOptions contains the optional parameters for BeginCreate
+type WidgetClientBeginCreateOptions struct{}
+
+// WidgetClientCreateResponse is the response of BeginCreate
+type WidgetClientCreateResponse struct{}
+
+// WidgetClientBeginDeleteOptions contains the optional parameters for BeginDelete
+type WidgetClientBeginDeleteOptions struct{}
+
+// WidgetClientDeleteResponse is the response of BeginDelete
+type WidgetClientDeleteResponse struct{}
+
+// WidgetClientRestoreOptions contains the optional parameters for Restore
+type WidgetClientRestoreOptions struct{}
+
+// WidgetClientRestoreResponse is the response of Restore
+type WidgetClientRestoreResponse struct{}
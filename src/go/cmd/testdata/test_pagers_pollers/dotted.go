@@ -0,0 +1,40 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_pagers_pollers
+
+import (
+	"context"
+
+	. "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// GadgetClient manages gadgets, dot importing azcore's runtime package
+type GadgetClient struct{}
+
+// NewListPager returns a pager of the right response
+func (c *GadgetClient) NewListPager(options *GadgetClientListOptions) *Pager[GadgetClientListResponse] {
+	return nil
+}
+
+// Reload returns a poller but isn't named like a long-running operation
+func (c *GadgetClient) Reload(ctx context.Context, options *GadgetClientReloadOptions) (*Poller[GadgetClientReloadResponse], error) {
+	return nil, nil
+}
+
+// Beginning isn't a long-running operation, because "Begin" doesn't start a word of its name
+func (c *GadgetClient) Beginning() string {
+	return ""
+}
+
+// GadgetClientListOptions contains the optional parameters for NewListPager
+type GadgetClientListOptions struct{}
+
+// GadgetClientListResponse is the response of NewListPager
+type GadgetClientListResponse struct{}
+
+// GadgetClientReloadOptions contains the optional parameters for Reload
+type GadgetClientReloadOptions struct{}
+
+// GadgetClientReloadResponse is the response of Reload
+type GadgetClientReloadResponse struct{}
@@ -0,0 +1,3 @@
+module test_pagers_pollers
+
+go 1.21
@@ -0,0 +1,12 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_platforms
+
+// Conn is a connection available on all platforms
+type Conn struct{}
+
+// Close closes the connection
+func (c *Conn) Close() error {
+	return nil
+}
@@ -0,0 +1,14 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build unix
+
+package test_platforms
+
+// DefaultPath is the default socket path
+const DefaultPath = "/tmp/conn"
+
+// Handle returns the connection's file descriptor
+func (c *Conn) Handle() int {
+	return 0
+}
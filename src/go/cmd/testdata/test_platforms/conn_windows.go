@@ -0,0 +1,12 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_platforms
+
+// DefaultPath is the default pipe path
+const DefaultPath = `\\.\pipe\conn`
+
+// Handle returns the connection's handle
+func (c *Conn) Handle() uintptr {
+	return 0
+}
@@ -0,0 +1,11 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build !linux
+
+package test_platforms
+
+// Dial connects to the default path
+func Dial() (*Conn, error) {
+	return &Conn{}, nil
+}
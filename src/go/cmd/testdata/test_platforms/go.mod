@@ -0,0 +1,3 @@
+module test_platforms
+
+go 1.21
@@ -0,0 +1,9 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build debug
+
+package test_platforms
+
+// Trace logs connection activity
+func Trace() {}
@@ -0,0 +1,3 @@
+module test_promoted
+
+go 1.21
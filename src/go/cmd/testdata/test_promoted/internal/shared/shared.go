@@ -0,0 +1,9 @@
+package shared
+
+type Base struct {
+	Endpoint string
+}
+
+func (b *Base) URL() string {
+	return b.Endpoint
+}
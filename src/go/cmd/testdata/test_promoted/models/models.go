@@ -0,0 +1,16 @@
+package models
+
+type Model struct {
+	ID *string
+	Inner
+}
+
+func (m Model) Validate() error {
+	return nil
+}
+
+type Inner struct {
+	ETag string
+}
+
+func (i *Inner) Reset() {}
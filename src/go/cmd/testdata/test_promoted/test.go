@@ -0,0 +1,92 @@
+package test_promoted
+
+import (
+	"test_promoted/internal/shared"
+	"test_promoted/models"
+)
+
+// Base is hoisted from an internal package
+type Base = shared.Base
+
+// Client embeds an alias hoisted type
+type Client struct {
+	Base
+}
+
+// FooResponse embeds a model from another package
+type FooResponse struct {
+	models.Model
+	RawResponse string
+}
+
+// Shadow declares a field hiding a promoted field
+type Shadow struct {
+	models.Model
+	ID int
+}
+
+func (s Shadow) Validate() error {
+	return nil
+}
+
+type A struct {
+	Name  string
+	OnlyA int
+}
+
+type B struct {
+	Name  string
+	OnlyB int
+}
+
+// Both promotes Name from A and B, which is ambiguous
+type Both struct {
+	A
+	B
+}
+
+type Reader interface {
+	Read() ([]byte, error)
+}
+
+// ReadCloser embeds an interface
+type ReadCloser interface {
+	Reader
+	Close() error
+}
+
+type Shared struct {
+	Common string
+}
+
+type Left struct {
+	Shared
+	OnlyLeft int
+}
+
+type Right struct {
+	Shared
+	OnlyRight int
+}
+
+// Diamond embeds Shared through Left and Right, which makes Shared and its members ambiguous
+type Diamond struct {
+	Left
+	Right
+}
+
+type Flusher interface {
+	Reader
+	Flush()
+}
+
+type Syncer interface {
+	Reader
+	Sync()
+}
+
+// FlushSyncer embeds Reader through Flusher and Syncer, which share its method
+type FlushSyncer interface {
+	Flusher
+	Syncer
+}
@@ -0,0 +1,7 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_stray_packages
+
+// Client is the library's only export
+type Client struct{}
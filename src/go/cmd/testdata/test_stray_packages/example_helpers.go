@@ -0,0 +1,7 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_stray_packages_test
+
+// Helper is an external test helper whose file name lacks the "_test.go" suffix
+func Helper() {}
@@ -0,0 +1,10 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// This generator has no build constraint, so it's parsed with the library.
+package main
+
+func main() {}
+
+// Generate isn't part of the library's API
+func Generate() {}
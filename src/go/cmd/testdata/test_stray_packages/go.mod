@@ -0,0 +1,3 @@
+module test_stray_packages
+
+go 1.21
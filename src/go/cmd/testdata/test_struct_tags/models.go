@@ -0,0 +1,49 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_struct_tags
+
+import "encoding/json"
+
+// Widget is a model whose fields all have json tags
+type Widget struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty" xml:"name"`
+	Notes string `json:"-"`
+	count int
+}
+
+// Gadget is a model having a field without a json tag and fields having the same wire name
+type Gadget struct {
+	Kind  string `json:"kind"`
+	Type  string `json:"kind,omitempty"`
+	Label string
+}
+
+// Custom marshals itself, so its json tags are ignored
+type Custom struct {
+	Value string `json:"value"`
+}
+
+func (c Custom) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Value)
+}
+
+func (c *Custom) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &c.Value)
+}
+
+// Outgoing customizes only marshaling, so unmarshaling uses its json tags
+type Outgoing struct {
+	Value string `json:"value"`
+	Label string
+}
+
+func (o Outgoing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Value)
+}
+
+// Options isn't a model because none of its fields have json tags
+type Options struct {
+	Retries int
+}
@@ -0,0 +1,3 @@
+module test_type_check
+
+go 1.21
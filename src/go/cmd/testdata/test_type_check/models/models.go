@@ -0,0 +1,5 @@
+package models
+
+type Widget struct {
+	Name string
+}
@@ -0,0 +1,28 @@
+package test_type_check
+
+import (
+	"time"
+
+	. "test_type_check/models"
+)
+
+type Request struct{}
+
+// Handler's parameter name isn't a type
+type Handler func(req *Request) error
+
+// Client's fields refer to a dot imported type and a type from the standard library
+type Client struct {
+	Timeout time.Duration
+	Widget  *Widget
+}
+
+// Get returns a type from a dot imported package
+func (c *Client) Get() Widget {
+	return Widget{}
+}
+
+// First has a type parameter
+func First[T any](s []T) T {
+	return s[0]
+}
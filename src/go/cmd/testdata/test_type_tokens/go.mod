@@ -0,0 +1,3 @@
+module test_type_tokens
+
+go 1.21
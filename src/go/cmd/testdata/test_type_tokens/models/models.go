@@ -0,0 +1,10 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package models
+
+// Item is an item
+type Item struct{}
+
+// List is a generic list
+type List[T any] []T
@@ -0,0 +1,32 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_type_tokens
+
+import "test_type_tokens/models"
+
+// Event is an event
+type Event struct{}
+
+// Handler handles events
+type Handler func(ctx Event, retries int) (handled bool, err error)
+
+// Stream has fields of types the tokenizer must walk
+type Stream struct {
+	Events  <-chan Event
+	Acks    chan<- bool
+	Both    chan Event
+	Options struct {
+		Name  string `json:"name"`
+		Retry int
+	}
+	Logger interface {
+		Log(msg string)
+	}
+	Items models.List[models.Item]
+}
+
+// Subscribe subscribes to events
+func Subscribe(handler func(e Event) error, done <-chan struct{}) map[string][]*models.Item {
+	return nil
+}
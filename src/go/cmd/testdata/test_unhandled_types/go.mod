@@ -0,0 +1,3 @@
+module test_unhandled_types
+
+go 1.21
@@ -0,0 +1,12 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_unhandled_types
+
+// Events is a channel type, which the indexer reports as an unhandled node type
+type Events chan int
+
+// Widget is indexed as usual
+type Widget struct {
+	Name string
+}
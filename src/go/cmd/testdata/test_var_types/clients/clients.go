@@ -0,0 +1,12 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package clients
+
+// Client is defined in another package of the module
+type Client struct{}
+
+// New returns a Client
+func New() *Client {
+	return &Client{}
+}
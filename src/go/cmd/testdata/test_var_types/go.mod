@@ -0,0 +1,3 @@
+module test_var_types
+
+go 1.21
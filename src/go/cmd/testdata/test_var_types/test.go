@@ -0,0 +1,53 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package test_var_types
+
+import (
+	"errors"
+
+	"test_var_types/clients"
+)
+
+// Widget is a type whose vars are initialized by calls
+type Widget struct{}
+
+// NewWidget returns a Widget
+func NewWidget() *Widget {
+	return &Widget{}
+}
+
+// Box holds a value
+type Box[T any] struct {
+	Value T
+}
+
+// NewBox returns a Box holding v
+func NewBox[T any](v T) *Box[T] {
+	return &Box[T]{Value: v}
+}
+
+// Split returns a Widget and an error
+func Split() (Widget, error) {
+	return Widget{}, nil
+}
+
+var (
+	// DefaultWidget is initialized by a constructor
+	DefaultWidget = NewWidget()
+
+	// DefaultClient is initialized by a call into another package of the module
+	DefaultClient = clients.New()
+
+	// IntBox is initialized by an explicitly instantiated generic func
+	IntBox = NewBox[int](42)
+
+	// StringBox is initialized by a generic func whose type argument is inferred
+	StringBox = NewBox("hello")
+
+	// ErrFailed is initialized by a func outside the module, whose type isn't known
+	ErrFailed = errors.New("failed")
+
+	// First and Second are initialized by a call returning two results
+	First, Second = Split()
+)
@@ -6,6 +6,7 @@ package cmd
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
 	"regexp"
 	"sort"
 	"strings"
@@ -20,67 +21,62 @@ var exportedFieldRgx = regexp.MustCompile(`^(?:[a-zA-Z]*\.)?[A-Z]+[a-zA-Z]*`)
 type TokenMaker interface {
 	Exported() bool
 	ID() string
-	MakeTokens() []Token
+	// MakeLines returns the lines of the declaration, and of its members nested under it
+	MakeLines() []tokenLine
 	Name() string
+	// Position returns the location of the declaration's name in its source file
+	Position() token.Position
 }
 
 // Declaration is a const or var declaration.
 type Declaration struct {
-	Type string
+	Type typeExpr
 
+	// doc is the declaration's doc comment, one element per line
+	doc []string
+	// expr is the source expression of a const whose value is evaluated, when it differs from the value
+	expr  string
 	id    string
 	name  string
+	pos   token.Position
 	value string
 }
 
-func NewDeclaration(pkg Pkg, vs *ast.ValueSpec, imports map[string]string) Declaration {
+// NewDeclaration returns the declaration of the ValueSpec's i-th name
+func NewDeclaration(pkg Pkg, vs *ast.ValueSpec, i int, imports map[string]string) Declaration {
+	name := vs.Names[i]
 	v := skip
-	if len(vs.Values) > 0 {
-		v = getExprValue(pkg, vs.Values[0])
+	var value ast.Expr
+	if i < len(vs.Values) {
+		value = vs.Values[i]
+	} else if len(vs.Values) == 1 {
+		// var a, b = f()
+		value = vs.Values[0]
+	}
+	if value != nil {
+		v = getExprValue(pkg, value)
 	}
-	decl := Declaration{id: pkg.Name() + "." + vs.Names[0].Name, name: vs.Names[0].Name, value: v}
+	decl := Declaration{doc: docLines(vs.Doc), id: pkg.Name() + "." + name.Name, name: name.Name, pos: pkg.fs.Position(name.Pos()), value: v}
 	// Type is nil for untyped consts
 	if vs.Type != nil {
-		switch x := vs.Type.(type) {
-		case *ast.Ident:
-			// const ETagAny ETag = "*"
-			// const PeekLock ReceiveMode = internal.PeekLock
-			decl.Type = pkg.translateType(x.Name, imports)
-		case *ast.MapType:
-			// var nullables map[reflect.Type]interface{} = map[reflect.Type]interface{}{}
-			decl.Type = pkg.translateType(pkg.getText(vs.Type.Pos(), vs.Type.End()), imports)
-		case *ast.SelectorExpr:
-			// const LogCredential log.Classification = "Credential"
-			decl.Type = pkg.translateType(x.Sel.Name, imports)
-		case *ast.StarExpr:
-			switch xX := x.X.(type) {
-			case *ast.Ident:
-				// var SomeTypeValue *SomeType
-				decl.Type = pkg.translateType("*"+xX.Name, imports)
-			case *ast.SelectorExpr:
-				// var defaultHTTPClient *http.Client
-				decl.Type = pkg.translateType(fmt.Sprintf("*%s.%s", xX.X, xX.Sel.Name), imports)
-			default:
-				fmt.Printf("unhandled declaration type %T for %s\n", xX, pkg.getText(vs.Type.Pos(), vs.Type.End()))
-			}
-		default:
-			fmt.Println("unhandled declaration " + pkg.getText(vs.Type.Pos(), vs.Type.End()))
-		}
-	} else if len(vs.Values) == 1 {
-		switch t := vs.Values[0].(type) {
+		// const ETagAny ETag = "*"
+		// const LogCredential log.Classification = "Credential"
+		// var defaultHTTPClient *http.Client
+		decl.Type = pkg.translateExpr(vs.Type, imports)
+	} else if value != nil {
+		switch t := value.(type) {
 		case *ast.CallExpr:
 			// const FooConst = Foo("value")
 			// var Foo = NewFoo()
-			// TODO: determining the type here requires finding the definition of the called function. We may
-			// not have encountered that yet, so we would need to set the types of these declarations after
-			// traversing the entire AST.
+			// determining the type here requires finding the definition of the called function, which may not
+			// have been indexed yet. Module.resolveVarTypes sets the type after indexing the entire module.
 		case *ast.CompositeLit:
 			// var AzureChina = Configuration{ ... }
-			decl.Type = pkg.translateType(pkg.getText(t.Type.Pos(), t.Type.End()), imports)
+			decl.Type = pkg.translateExpr(t.Type, imports)
 		}
 	} else {
 		// implicitly typed const
-		decl.Type = skip
+		decl.Type = typeExpr{text: skip}
 	}
 	return decl
 }
@@ -93,32 +89,48 @@ func (d Declaration) ID() string {
 	return d.id
 }
 
-func (d Declaration) MakeTokens() []Token {
+// MakeLines returns lines for the declaration as it appears in a const or var block
+func (d Declaration) MakeLines() []tokenLine {
 	list := &[]Token{}
 	ID := d.ID()
+	deprecated := startDeprecatedRange(d.doc, list)
 	makeToken(&ID, nil, d.Name(), TokenTypeTypeName, list)
 	makeToken(nil, nil, " ", TokenTypeWhitespace, list)
-	if d.Type != skip {
-		parseAndMakeTypeToken(d.Type, list)
+	if d.Type.text != skip {
+		d.Type.makeTokens(list)
 	}
-	makeToken(nil, nil, " ", TokenTypeWhitespace, list)
-	makeToken(nil, nil, "=", TokenTypePunctuation, list)
-	makeToken(nil, nil, " ", TokenTypeWhitespace, list)
-	makeToken(nil, nil, d.value, TokenTypeStringLiteral, list)
-	makeToken(nil, nil, "", TokenTypeNewline, list)
-	return *list
+	if d.value != skip {
+		// "var a, b int" has no values
+		makeToken(nil, nil, " ", TokenTypeWhitespace, list)
+		makeToken(nil, nil, "=", TokenTypePunctuation, list)
+		makeToken(nil, nil, " ", TokenTypeWhitespace, list)
+		makeToken(nil, nil, d.value, TokenTypeStringLiteral, list)
+	}
+	if d.expr != "" {
+		makeToken(nil, nil, " ", TokenTypeWhitespace, list)
+		makeToken(nil, nil, "// "+d.expr, TokenTypeComment, list)
+	}
+	endDeprecatedRange(deprecated, list)
+	return append(makeDocLines(d.doc), tokenLine{tokens: *list})
 }
 
 func (d Declaration) Name() string {
 	return d.name
 }
 
+func (d Declaration) Position() token.Position {
+	return d.pos
+}
+
 type Func struct {
 	ReceiverName string
+	// ReceiverType is the source text of the method's receiver type e.g. "*Client"
 	ReceiverType string
 	// Returns lists the func's return types
-	Returns []string
+	Returns []typeExpr
 
+	// doc is the func's doc comment, one element per line
+	doc      []string
 	embedded bool
 	exported bool
 	id       string
@@ -127,19 +139,29 @@ type Func struct {
 	// paramNames lists the func's parameters name
 	paramNames []string
 	// paramTypes lists the func's parameters type
-	paramTypes []string
+	paramTypes []typeExpr
+	pos        token.Position
+	// receiver is the method's receiver type, whose identifiers aren't links
+	receiver typeExpr
 	// typeParamNames lists the func's type parameters name
 	typeParamNames []string
 	// typeParamConstraints lists the func's type parameters constraint
-	typeParamConstraints []string
+	typeParamConstraints []typeExpr
 }
 
 func NewFunc(pkg Pkg, f *ast.FuncDecl, imports map[string]string) Func {
 	fn := newFunc(pkg, f.Type, imports)
+	if f.Recv != nil {
+		// a method of a generic type can use the type's parameters e.g. "func (l *List[T]) Get() T"
+		fn.stripTypeParams(pkg, receiverTypeParams(f.Recv.List[0].Type))
+	}
+	fn.doc = docLines(f.Doc)
 	fn.name = f.Name.Name
+	fn.pos = pkg.fs.Position(f.Name.Pos())
 	sig := ""
 	if f.Recv != nil {
 		fn.ReceiverType = pkg.getText(f.Recv.List[0].Type.Pos(), f.Recv.List[0].Type.End())
+		fn.receiver = unlinkedType(pkg, f.Recv.List[0].Type)
 		if len(f.Recv.List[0].Names) != 0 {
 			fn.ReceiverName = f.Recv.List[0].Names[0].Name
 		}
@@ -157,7 +179,9 @@ func NewFunc(pkg Pkg, f *ast.FuncDecl, imports map[string]string) Func {
 
 func NewFuncForInterfaceMethod(pkg Pkg, interfaceName string, f *ast.Field, imports map[string]string) Func {
 	fn := newFunc(pkg, f.Type.(*ast.FuncType), imports)
+	fn.doc = docLines(f.Doc)
 	fn.name = f.Names[0].Name
+	fn.pos = pkg.fs.Position(f.Names[0].Pos())
 	fn.exported = unicode.IsUpper(rune(fn.name[0]))
 	fn.id = pkg.Name() + "-" + interfaceName + "-" + fn.name
 	fn.embedded = true
@@ -166,35 +190,42 @@ func NewFuncForInterfaceMethod(pkg Pkg, interfaceName string, f *ast.Field, impo
 
 func newFunc(pkg Pkg, f *ast.FuncType, imports map[string]string) Func {
 	fn := Func{}
-	if f.TypeParams != nil {
-		fn.typeParamNames = make([]string, 0, len(f.TypeParams.List))
-		fn.typeParamConstraints = make([]string, 0, len(f.TypeParams.List))
-		pkg.translateFieldList(f.TypeParams.List, func(param *string, constraint string) {
-			fn.typeParamNames = append(fn.typeParamNames, *param)
-			fn.typeParamConstraints = append(fn.typeParamConstraints, pkg.translateType(strings.TrimRight(constraint, " "), imports))
-		})
-	}
+	fn.typeParamNames, fn.typeParamConstraints = newTypeParams(pkg, f.TypeParams, imports)
 	if f.Params.List != nil {
 		fn.paramNames = make([]string, 0, len(f.Params.List))
-		fn.paramTypes = make([]string, 0, len(f.Params.List))
-		pkg.translateFieldList(f.Params.List, func(n *string, t string) {
+		fn.paramTypes = make([]typeExpr, 0, len(f.Params.List))
+		pkg.translateFieldList(f.Params.List, func(n *string, t ast.Expr) {
 			if n != nil {
 				fn.paramNames = append(fn.paramNames, *n)
 			} else {
 				fn.paramNames = append(fn.paramNames, "")
 			}
-			fn.paramTypes = append(fn.paramTypes, pkg.translateType(t, imports))
+			fn.paramTypes = append(fn.paramTypes, pkg.translateExpr(t, imports))
 		})
 	}
 	if f.Results != nil {
-		fn.Returns = make([]string, 0, len(f.Results.List))
-		pkg.translateFieldList(f.Results.List, func(n *string, t string) {
-			fn.Returns = append(fn.Returns, pkg.translateType(t, imports))
+		fn.Returns = make([]typeExpr, 0, len(f.Results.List))
+		pkg.translateFieldList(f.Results.List, func(n *string, t ast.Expr) {
+			fn.Returns = append(fn.Returns, pkg.translateExpr(t, imports))
 		})
 	}
+	fn.stripTypeParams(pkg, fn.typeParamNames)
 	return fn
 }
 
+// stripTypeParams removes the links translateExpr makes for the given type parameters where the func uses them
+func (f *Func) stripTypeParams(pkg Pkg, names []string) {
+	for i := range f.typeParamConstraints {
+		f.typeParamConstraints[i] = pkg.stripTypeParams(f.typeParamConstraints[i], names)
+	}
+	for i := range f.paramTypes {
+		f.paramTypes[i] = pkg.stripTypeParams(f.paramTypes[i], names)
+	}
+	for i := range f.Returns {
+		f.Returns[i] = pkg.stripTypeParams(f.Returns[i], names)
+	}
+}
+
 func (f Func) Exported() bool {
 	return f.exported
 }
@@ -214,11 +245,10 @@ func (f Func) ForAlias(pkg string) Func {
 	return clone
 }
 
-func (f Func) MakeTokens() []Token {
+func (f Func) MakeLines() []tokenLine {
 	list := &[]Token{}
-	if f.embedded {
-		makeToken(nil, nil, "\t", TokenTypeWhitespace, list)
-	} else {
+	deprecated := startDeprecatedRange(f.doc, list)
+	if !f.embedded {
 		makeToken(nil, nil, "func", TokenTypeKeyword, list)
 		makeToken(nil, nil, " ", TokenTypeWhitespace, list)
 	}
@@ -228,34 +258,38 @@ func (f Func) MakeTokens() []Token {
 			makeToken(nil, nil, f.ReceiverName, TokenTypeMemberName, list)
 			makeToken(nil, nil, " ", TokenTypeWhitespace, list)
 		}
-		parseAndMakeTypeToken(f.ReceiverType, list)
+		f.receiver.makeTokens(list)
 		makeToken(nil, nil, ")", TokenTypePunctuation, list)
 		makeToken(nil, nil, " ", TokenTypeWhitespace, list)
 	}
 	ID := f.ID()
 	makeToken(&ID, nil, f.name, TokenTypeTypeName, list)
-	if len(f.typeParamNames) > 0 {
-		makeToken(nil, nil, "[", TokenTypePunctuation, list)
-		for i, p := range f.typeParamNames {
-			if i > 0 {
-				makeToken(nil, nil, ",", TokenTypePunctuation, list)
-				makeToken(nil, nil, " ", TokenTypeWhitespace, list)
-			}
-			makeToken(nil, nil, p, TokenTypeMemberName, list)
-			makeToken(nil, nil, " ", TokenTypeWhitespace, list)
-			parseAndMakeTypeToken(f.typeParamConstraints[i], list)
-		}
-		makeToken(nil, nil, "]", TokenTypePunctuation, list)
+	f.makeSignatureTokens(list)
+	endDeprecatedRange(deprecated, list)
+	lines := append(makeDocLines(f.doc), tokenLine{tokens: *list})
+	if !f.embedded {
+		lines = append(lines, blankLine)
 	}
+	return lines
+}
+
+func (f Func) Name() string {
+	return f.name
+}
+
+// makeSignatureTokens makes tokens for the func's signature following its name i.e. its type
+// parameters, parameters and results
+func (f Func) makeSignatureTokens(list *[]Token) {
+	makeTypeParamTokens(f.typeParamNames, f.typeParamConstraints, list)
 	makeToken(nil, nil, "(", TokenTypePunctuation, list)
 	for i, p := range f.paramNames {
 		if p != "" {
 			makeToken(nil, nil, p, TokenTypeMemberName, list)
 			makeToken(nil, nil, " ", TokenTypeWhitespace, list)
-			parseAndMakeTypeToken(f.paramTypes[i], list)
+			f.paramTypes[i].makeTokens(list)
 		} else {
 			// parameter names are optional
-			parseAndMakeTypeToken(f.paramTypes[i], list)
+			f.paramTypes[i].makeTokens(list)
 		}
 		if i < len(f.paramNames)-1 {
 			makeToken(nil, nil, ",", TokenTypePunctuation, list)
@@ -269,7 +303,7 @@ func (f Func) MakeTokens() []Token {
 			makeToken(nil, nil, "(", TokenTypePunctuation, list)
 		}
 		for i, t := range f.Returns {
-			parseAndMakeTypeToken(t, list)
+			t.makeTokens(list)
 			if i < len(f.Returns)-1 {
 				makeToken(nil, nil, ",", TokenTypePunctuation, list)
 				makeToken(nil, nil, " ", TokenTypeWhitespace, list)
@@ -279,15 +313,10 @@ func (f Func) MakeTokens() []Token {
 			makeToken(nil, nil, ")", TokenTypePunctuation, list)
 		}
 	}
-	if !f.embedded {
-		makeToken(nil, nil, "", TokenTypeNewline, list)
-	}
-	makeToken(nil, nil, "", TokenTypeNewline, list)
-	return *list
 }
 
-func (f Func) Name() string {
-	return f.name
+func (f Func) Position() token.Position {
+	return f.pos
 }
 
 var _ TokenMaker = (*Func)(nil)
@@ -295,36 +324,70 @@ var _ TokenMaker = (*Func)(nil)
 type Interface struct {
 	TokenMaker
 	// Sealed indicates whether users can implement the interface i.e. whether it has an unexported method
-	Sealed             bool
-	embeddedInterfaces []string
+	Sealed bool
+	// doc is the interface's doc comment, one element per line
+	doc                []string
+	embeddedInterfaces []typeExpr
 	id                 string
 	methods            map[string]Func
 	name               string
+	pos                token.Position
+	// typeParamNames lists the interface's type parameters name
+	typeParamNames []string
+	// typeParamConstraints lists the interface's type parameters constraint
+	typeParamConstraints []typeExpr
+	// typeSet lists the interface's union elements, which constrain its type set e.g. "~int | ~string"
+	typeSet [][]typeTerm
 }
 
-func NewInterface(source Pkg, name, packageName string, n *ast.InterfaceType, imports map[string]string) Interface {
+// typeTerm is a term of a union in a constraint interface e.g. "~int"
+type typeTerm struct {
+	// tilde is true for terms denoting all types having the term's type as their underlying type
+	tilde bool
+	// typ is the term's type
+	typ typeExpr
+}
+
+func NewInterface(source Pkg, name, packageName string, ts *ast.TypeSpec, imports map[string]string) Interface {
 	in := Interface{
 		name:               name,
-		embeddedInterfaces: []string{},
+		embeddedInterfaces: []typeExpr{},
 		methods:            map[string]Func{},
 		id:                 packageName + "." + name,
+		pos:                source.fs.Position(ts.Name.Pos()),
 	}
+	in.typeParamNames, in.typeParamConstraints = newTypeParams(source, ts.TypeParams, imports)
+	n := ts.Type.(*ast.InterfaceType)
 	if n.Methods != nil {
 		for _, m := range n.Methods.List {
-			if len(m.Names) > 0 {
+			switch t := m.Type.(type) {
+			case *ast.FuncType:
 				n := m.Names[0].Name
 				if unicode.IsLower(rune(n[0])) {
 					in.Sealed = true
 				}
 				f := NewFuncForInterfaceMethod(source, name, m, imports)
+				f.stripTypeParams(source, in.typeParamNames)
 				in.methods[n] = f
-			} else {
-				n := source.getText(m.Type.Pos(), m.Type.End())
-				in.embeddedInterfaces = append(in.embeddedInterfaces, source.translateType(n, imports))
+			case *ast.BinaryExpr, *ast.UnaryExpr:
+				// union or tilde element e.g. "~int | ~string"
+				union := []typeTerm{}
+				for _, term := range unionTerms(t) {
+					tt := typeTerm{}
+					if u, ok := term.(*ast.UnaryExpr); ok && u.Op == token.TILDE {
+						tt.tilde = true
+						term = u.X
+					}
+					tt.typ = source.stripTypeParams(source.translateExpr(term, imports), in.typeParamNames)
+					union = append(union, tt)
+				}
+				in.typeSet = append(in.typeSet, union)
+			default:
+				in.embeddedInterfaces = append(in.embeddedInterfaces, source.stripTypeParams(source.translateExpr(m.Type, imports), in.typeParamNames))
 			}
 		}
 	}
-	sort.Strings(in.embeddedInterfaces)
+	sortTypes(in.embeddedInterfaces)
 	return in
 }
 
@@ -336,57 +399,80 @@ func (i Interface) ID() string {
 	return i.id
 }
 
-func (i Interface) MakeTokens() []Token {
+func (i Interface) MakeLines() []tokenLine {
 	ID := i.id
 	list := &[]Token{}
+	deprecated := startDeprecatedRange(i.doc, list)
 	makeToken(nil, nil, "type", TokenTypeKeyword, list)
 	makeToken(nil, nil, " ", TokenTypeWhitespace, list)
 	makeToken(&ID, nil, i.name, TokenTypeTypeName, list)
+	makeTypeParamTokens(i.typeParamNames, i.typeParamConstraints, list)
 	makeToken(nil, nil, " ", TokenTypeWhitespace, list)
 	makeToken(nil, nil, "interface", TokenTypeKeyword, list)
 	makeToken(nil, nil, " ", TokenTypeWhitespace, list)
 	makeToken(nil, nil, "{", TokenTypePunctuation, list)
-	for _, name := range i.embeddedInterfaces {
-		if exportedFieldRgx.MatchString(name) {
-			// defID := ID + "-" + name
-			makeToken(nil, nil, "", TokenTypeNewline, list)
-			makeToken(nil, nil, "\t", TokenTypeWhitespace, list)
-			parseAndMakeTypeToken(name, list)
-			// makeToken(&defID, nil, name, TokenTypeTypeName, list)
+	members := []tokenLine{}
+	for _, e := range i.embeddedInterfaces {
+		// interfaces defined in the module aren't listed; Options.Promoted lists the methods they promote
+		if e.navigator() == "" && exportedFieldRgx.MatchString(e.text) {
+			embedded := &[]Token{}
+			e.makeTokens(embedded)
+			members = append(members, tokenLine{tokens: *embedded})
 		}
 	}
-	if len(i.methods) > 0 {
-		makeToken(nil, nil, "", TokenTypeNewline, list)
-		keys := []string{}
-		for k := range i.methods {
-			if unicode.IsUpper(rune(k[0])) {
-				keys = append(keys, k)
+	for _, union := range i.typeSet {
+		terms := &[]Token{}
+		for j, term := range union {
+			if j > 0 {
+				makeToken(nil, nil, " ", TokenTypeWhitespace, terms)
+				makeToken(nil, nil, "|", TokenTypePunctuation, terms)
+				makeToken(nil, nil, " ", TokenTypeWhitespace, terms)
 			}
+			if term.tilde {
+				makeToken(nil, nil, "~", TokenTypePunctuation, terms)
+			}
+			term.typ.makeTokens(terms)
 		}
-		sort.Strings(keys)
-		for _, k := range keys {
-			*list = append(*list, i.methods[k].MakeTokens()...)
+		members = append(members, tokenLine{tokens: *terms})
+	}
+	keys := []string{}
+	for k := range i.methods {
+		if unicode.IsUpper(rune(k[0])) {
+			keys = append(keys, k)
 		}
 	}
-	makeToken(nil, nil, "}", TokenTypePunctuation, list)
-	makeToken(nil, nil, "", TokenTypeNewline, list)
-	makeToken(nil, nil, "", TokenTypeNewline, list)
-	return *list
+	sort.Strings(keys)
+	for _, k := range keys {
+		members = append(members, i.methods[k].MakeLines()...)
+	}
+	// an interface having only unexported methods has an empty block rather than "{}"
+	return append(makeDocLines(i.doc), makeBlockLines(*list, members, len(members) > 0 || len(i.methods) > 0, deprecated)...)
 }
 
 func (i Interface) Name() string {
 	return i.name
 }
 
+func (i Interface) Position() token.Position {
+	return i.pos
+}
+
 var _ TokenMaker = (*Interface)(nil)
 
 type SimpleType struct {
-	id             string
-	name           string
-	underlyingType string
+	// doc is the type's doc comment, one element per line
+	doc  []string
+	id   string
+	name string
+	pos  token.Position
+	// typeParamNames lists the type's type parameters name
+	typeParamNames []string
+	// typeParamConstraints lists the type's type parameters constraint
+	typeParamConstraints []typeExpr
+	underlyingType       typeExpr
 }
 
-func NewSimpleType(name, packageName, underlyingType string) SimpleType {
+func NewSimpleType(name, packageName string, underlyingType typeExpr) SimpleType {
 	return SimpleType{id: packageName + "." + name, name: name, underlyingType: underlyingType}
 }
 
@@ -398,56 +484,95 @@ func (s SimpleType) ID() string {
 	return s.id
 }
 
-func (s SimpleType) MakeTokens() []Token {
+func (s SimpleType) MakeLines() []tokenLine {
 	tokenList := &[]Token{}
 	ID := s.id
+	deprecated := startDeprecatedRange(s.doc, tokenList)
 	makeToken(nil, nil, "type", TokenTypeKeyword, tokenList)
 	makeToken(nil, nil, " ", TokenTypeWhitespace, tokenList)
 	makeToken(&ID, nil, s.name, TokenTypeTypeName, tokenList)
+	makeTypeParamTokens(s.typeParamNames, s.typeParamConstraints, tokenList)
 	makeToken(nil, nil, " ", TokenTypeWhitespace, tokenList)
-	parseAndMakeTypeToken(s.underlyingType, tokenList)
-	// makeToken(nil, nil, s.underlyingType, TokenTypeText, tokenList)
-	makeToken(nil, nil, "", TokenTypeNewline, tokenList)
-	makeToken(nil, nil, "", TokenTypeNewline, tokenList)
-	return *tokenList
+	s.underlyingType.makeTokens(tokenList)
+	endDeprecatedRange(deprecated, tokenList)
+	return append(makeDocLines(s.doc), tokenLine{tokens: *tokenList}, blankLine)
 }
 
 func (s SimpleType) Name() string {
 	return s.name
 }
 
+func (s SimpleType) Position() token.Position {
+	return s.pos
+}
+
 var _ TokenMaker = (*SimpleType)(nil)
 
 type Struct struct {
-	AnonymousFields []string
-	// fields maps a field's name to the name of its type
-	fields map[string]string
+	// AnonymousFields lists the types of the struct's anonymous fields, whose identifiers aren't links, sorted
+	AnonymousFields []typeExpr
+	// embedded lists the types of the struct's anonymous fields in source order
+	embedded []typeExpr
+	// doc is the struct's doc comment, one element per line
+	doc []string
+	// fieldDocs maps a field's name to its doc comment
+	fieldDocs map[string][]string
+	// fieldPositions maps a field's name to its location
+	fieldPositions map[string]token.Position
+	// fieldTags maps a field's name to its tag, a string literal e.g. `json:"name,omitempty"`
+	fieldTags map[string]string
+	// fields maps a field's name to its type
+	fields map[string]typeExpr
 	id     string
 	name   string
-	// typeParams lists the func's type parameters as strings of the form "name constraint"
-	typeParams []string
-	pkgName    string
+	pos    token.Position
+	// typeParamNames lists the struct's type parameters name
+	typeParamNames []string
+	// typeParamConstraints lists the struct's type parameters constraint
+	typeParamConstraints []typeExpr
+	pkgName              string
+	// showTags is true when the struct's tokens include field tags
+	showTags bool
 }
 
 func NewStruct(source Pkg, name, packageName string, ts *ast.TypeSpec, imports map[string]string) Struct {
-	s := Struct{name: name, id: packageName + "." + name, pkgName: source.Name()}
-	if ts.TypeParams != nil {
-		s.typeParams = make([]string, 0, len(ts.TypeParams.List))
-		source.translateFieldList(ts.TypeParams.List, func(param *string, constraint string) {
-			s.typeParams = append(s.typeParams, strings.TrimRight(*param+" "+source.translateType(constraint, imports), " "))
-		})
-	}
-	source.translateFieldList(ts.Type.(*ast.StructType).Fields.List, func(n *string, t string) {
+	s := Struct{doc: docLines(ts.Doc), name: name, id: packageName + "." + name, pkgName: source.Name(), pos: source.fs.Position(ts.Name.Pos())}
+	s.typeParamNames, s.typeParamConstraints = newTypeParams(source, ts.TypeParams, imports)
+	fields := ts.Type.(*ast.StructType).Fields.List
+	source.translateFieldList(fields, func(n *string, t ast.Expr) {
 		if n == nil {
-			s.AnonymousFields = append(s.AnonymousFields, t)
+			s.AnonymousFields = append(s.AnonymousFields, unlinkedType(source, t))
+			s.embedded = append(s.embedded, source.stripTypeParams(source.translateExpr(t, imports), s.typeParamNames))
 		} else {
 			if s.fields == nil {
-				s.fields = map[string]string{}
+				s.fields = map[string]typeExpr{}
 			}
-			s.fields[*n] = source.translateType(t, imports)
+			s.fields[*n] = source.stripTypeParams(source.translateExpr(t, imports), s.typeParamNames)
 		}
 	})
-	sort.Strings(s.AnonymousFields)
+	for _, f := range fields {
+		for _, name := range f.Names {
+			if s.fieldPositions == nil {
+				s.fieldPositions = map[string]token.Position{}
+			}
+			s.fieldPositions[name.Name] = source.fs.Position(name.Pos())
+			if f.Tag != nil {
+				if s.fieldTags == nil {
+					s.fieldTags = map[string]string{}
+				}
+				s.fieldTags[name.Name] = f.Tag.Value
+			}
+		}
+		if doc := docLines(f.Doc); len(doc) > 0 {
+			if s.fieldDocs == nil {
+				s.fieldDocs = map[string][]string{}
+			}
+			for _, name := range f.Names {
+				s.fieldDocs[name.Name] = doc
+			}
+		}
+	}
+	sortTypes(s.AnonymousFields)
 	return s
 }
 
@@ -459,30 +584,24 @@ func (s Struct) ID() string {
 	return s.id
 }
 
-func (s Struct) MakeTokens() []Token {
+func (s Struct) MakeLines() []tokenLine {
 	list := &[]Token{}
 	ID := s.id
+	deprecated := startDeprecatedRange(s.doc, list)
 	makeToken(nil, nil, "type", TokenTypeKeyword, list)
 	makeToken(nil, nil, " ", TokenTypeWhitespace, list)
 	makeToken(&ID, nil, s.name, TokenTypeTypeName, list)
-	if len(s.typeParams) > 0 {
-		makeToken(nil, nil, "[", TokenTypePunctuation, list)
-		makeToken(nil, nil, strings.Join(s.typeParams, ", "), TokenTypeMemberName, list)
-		makeToken(nil, nil, "]", TokenTypePunctuation, list)
-	}
+	makeTypeParamTokens(s.typeParamNames, s.typeParamConstraints, list)
 	makeToken(nil, nil, " ", TokenTypeWhitespace, list)
 	makeToken(nil, nil, "struct", TokenTypeKeyword, list)
 	makeToken(nil, nil, " ", TokenTypeWhitespace, list)
 	makeToken(nil, nil, "{", TokenTypePunctuation, list)
-	exportedFields := false
-	for _, name := range s.AnonymousFields {
-		if exportedFieldRgx.MatchString(name) {
-			// defID := name + "-" + s.id
-			makeToken(nil, nil, "", TokenTypeNewline, list)
-			makeToken(nil, nil, "\t", TokenTypeWhitespace, list)
-			parseAndMakeTypeToken(name, list)
-			// makeToken(&defID, nil, name, TokenTypeTypeName, list)
-			exportedFields = true
+	fields := []tokenLine{}
+	for _, e := range s.AnonymousFields {
+		if exportedFieldRgx.MatchString(e.text) {
+			embedded := &[]Token{}
+			e.makeTokens(embedded)
+			fields = append(fields, tokenLine{tokens: *embedded})
 		}
 	}
 	keys := make([]string, 0, len(s.fields))
@@ -495,29 +614,98 @@ func (s Struct) MakeTokens() []Token {
 	for _, field := range keys {
 		typ := s.fields[field]
 		defID := field + "-" + s.id
-		makeToken(nil, nil, "", TokenTypeNewline, list)
-		makeToken(nil, nil, "\t", TokenTypeWhitespace, list)
-		makeToken(&defID, nil, field, TokenTypeTypeName, list)
-		makeToken(nil, nil, " ", TokenTypeWhitespace, list)
-		parseAndMakeTypeToken(typ, list)
-		// makeToken(nil, nil, typ, TokenTypeMemberName, list)
-		exportedFields = true
-	}
-	if exportedFields {
-		makeToken(nil, nil, "", TokenTypeNewline, list)
+		fields = append(fields, makeDocLines(s.fieldDocs[field])...)
+		fieldTokens := &[]Token{}
+		fieldDeprecated := startDeprecatedRange(s.fieldDocs[field], fieldTokens)
+		makeToken(&defID, nil, field, TokenTypeTypeName, fieldTokens)
+		makeToken(nil, nil, " ", TokenTypeWhitespace, fieldTokens)
+		typ.makeTokens(fieldTokens)
+		if tag, ok := s.fieldTags[field]; ok && s.showTags {
+			makeToken(nil, nil, " ", TokenTypeWhitespace, fieldTokens)
+			makeToken(nil, nil, tag, TokenTypeStringLiteral, fieldTokens)
+		}
+		endDeprecatedRange(fieldDeprecated, fieldTokens)
+		fields = append(fields, tokenLine{tokens: *fieldTokens})
 	}
-	makeToken(nil, nil, "}", TokenTypePunctuation, list)
-	makeToken(nil, nil, "", TokenTypeNewline, list)
-	makeToken(nil, nil, "", TokenTypeNewline, list)
-	return *list
+	return append(makeDocLines(s.doc), makeBlockLines(*list, fields, len(fields) > 0, deprecated)...)
 }
 
 func (s Struct) Name() string {
 	return s.name
 }
 
+func (s Struct) Position() token.Position {
+	return s.pos
+}
+
 var _ TokenMaker = (*Struct)(nil)
 
+// newTypeParams returns the names and constraints of a generic func or type's type parameters
+func newTypeParams(pkg Pkg, fl *ast.FieldList, imports map[string]string) (names []string, constraints []typeExpr) {
+	if fl == nil {
+		return nil, nil
+	}
+	names = make([]string, 0, len(fl.List))
+	constraints = make([]typeExpr, 0, len(fl.List))
+	pkg.translateFieldList(fl.List, func(param *string, constraint ast.Expr) {
+		names = append(names, *param)
+		constraints = append(constraints, pkg.translateExpr(constraint, imports))
+	})
+	// constraints can refer to type parameters e.g. "[S ~[]E, E any]"
+	for i := range constraints {
+		constraints[i] = pkg.stripTypeParams(constraints[i], names)
+	}
+	return names, constraints
+}
+
+// receiverTypeParams returns the names of the type parameters of a method's receiver e.g. "K" and "V" for "*Map[K, V]"
+func receiverTypeParams(recv ast.Expr) []string {
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	var indices []ast.Expr
+	switch x := recv.(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{x.Index}
+	case *ast.IndexListExpr:
+		indices = x.Indices
+	}
+	names := []string{}
+	for _, index := range indices {
+		if id, ok := index.(*ast.Ident); ok {
+			names = append(names, id.Name)
+		}
+	}
+	return names
+}
+
+// unionTerms flattens a union element like "~int | ~string | float64" into its terms
+func unionTerms(expr ast.Expr) []ast.Expr {
+	if b, ok := expr.(*ast.BinaryExpr); ok && b.Op == token.OR {
+		return append(unionTerms(b.X), unionTerms(b.Y)...)
+	}
+	return []ast.Expr{expr}
+}
+
+// makeTypeParamTokens makes tokens for a type parameter list like "[K comparable, V any]". It makes no tokens
+// when there are no type parameters.
+func makeTypeParamTokens(names []string, constraints []typeExpr, list *[]Token) {
+	if len(names) == 0 {
+		return
+	}
+	makeToken(nil, nil, "[", TokenTypePunctuation, list)
+	for i, p := range names {
+		if i > 0 {
+			makeToken(nil, nil, ",", TokenTypePunctuation, list)
+			makeToken(nil, nil, " ", TokenTypeWhitespace, list)
+		}
+		makeToken(nil, nil, p, TokenTypeMemberName, list)
+		makeToken(nil, nil, " ", TokenTypeWhitespace, list)
+		constraints[i].makeTokens(list)
+	}
+	makeToken(nil, nil, "]", TokenTypePunctuation, list)
+}
+
 // makeToken builds the Token to be added to the Token slice that is passed in as a parameter.
 // defID and navID components can be passed in as nil to indicate that there is no definition ID or
 // navigation ID that is related to that token.
@@ -531,51 +719,390 @@ func makeToken(defID, navID *string, val string, kind TokenType, list *[]Token)
 	*list = append(*list, tok)
 }
 
-func parseAndMakeTypeToken(val string, list *[]Token) {
-	now := ""
-	for _, ch := range val {
-		switch string(ch) {
-		case "*", "[", "]", " ", "(", ")", "{", "}", ",":
-			if now != "" {
-				makeTypeSectionToken(now, list)
-				now = ""
-			}
-			if string(ch) == " " {
-				makeToken(nil, nil, " ", TokenTypeWhitespace, list)
-			} else {
-				makeToken(nil, nil, string(ch), TokenTypePunctuation, list)
-			}
-		case ".":
-			if now == ".." {
-				makeToken(nil, nil, "...", TokenTypePunctuation, list)
-				now = ""
-			} else {
-				now = now + "."
+// docLines splits a doc comment into lines of text without comment markers. It returns nil for a nil comment.
+func docLines(cg *ast.CommentGroup) []string {
+	if cg == nil {
+		return nil
+	}
+	text := strings.TrimRight(cg.Text(), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// commentText formats a line of doc comment text as a line comment
+func commentText(line string) string {
+	if line == "" {
+		return "//"
+	}
+	return "// " + line
+}
+
+// makeDocLines returns a comment line for each line of doc
+func makeDocLines(doc []string) []tokenLine {
+	lines := make([]tokenLine, 0, len(doc))
+	for _, line := range doc {
+		list := &[]Token{}
+		makeToken(nil, nil, commentText(line), TokenTypeComment, list)
+		lines = append(lines, tokenLine{tokens: *list})
+	}
+	return lines
+}
+
+// makeBlockLines returns the lines of a struct or interface type whose definition, up to its opening "{", is def.
+// members are nested under the definition, followed by a line closing the block. When the block isn't open, the
+// type has nothing to list and is closed on the definition's line e.g. "type Foo struct {}". deprecated is true
+// when the definition started a deprecated range, which the closing brace ends. A blank line follows the type.
+func makeBlockLines(def []Token, members []tokenLine, open, deprecated bool) []tokenLine {
+	if !open {
+		makeToken(nil, nil, "}", TokenTypePunctuation, &def)
+		endDeprecatedRange(deprecated, &def)
+		return []tokenLine{{tokens: def}, blankLine}
+	}
+	closing := &[]Token{}
+	makeToken(nil, nil, "}", TokenTypePunctuation, closing)
+	endDeprecatedRange(deprecated, closing)
+	return []tokenLine{{tokens: def, children: members}, {tokens: *closing, closing: true}, blankLine}
+}
+
+// deprecationNotice returns the text of the "Deprecated: " paragraph of a doc comment, if it has one
+func deprecationNotice(doc []string) (string, bool) {
+	for i, line := range doc {
+		if !strings.HasPrefix(line, "Deprecated:") || (i > 0 && doc[i-1] != "") {
+			// the notice must begin a paragraph
+			continue
+		}
+		paragraph := []string{strings.TrimSpace(strings.TrimPrefix(line, "Deprecated:"))}
+		for _, l := range doc[i+1:] {
+			if l == "" {
+				break
 			}
-		default:
-			now = now + string(ch)
+			paragraph = append(paragraph, l)
 		}
+		return strings.TrimSpace(strings.Join(paragraph, " ")), true
 	}
-	if now != "" {
-		makeTypeSectionToken(now, list)
+	return "", false
+}
+
+// startDeprecatedRange appends a token starting a deprecated range when doc has a deprecation notice.
+// It returns true when it started a range, which the caller must end with endDeprecatedRange.
+func startDeprecatedRange(doc []string, list *[]Token) bool {
+	if _, ok := deprecationNotice(doc); !ok {
+		return false
 	}
+	makeToken(nil, nil, "", TokenTypeDeprecatedRangeStart, list)
+	return true
 }
 
-var keywords = []string{"interface", "map", "any", "func"}
-var internalTypes = []string{"bool", "uint8", "uint16", "uint32", "uint64", "uint", "int8", "int16", "int32", "int64", "int", "float32", "float64", "complex64", "complex128", "byte", "rune", "string", "error", "uintptr", "nil"}
+// endDeprecatedRange appends a token ending a deprecated range when started is true
+func endDeprecatedRange(started bool, list *[]Token) {
+	if started {
+		makeToken(nil, nil, "", TokenTypeDeprecatedRangeEnd, list)
+	}
+}
+
+// typeExpr is a type expression and the tokens representing it, which link identifiers denoting types
+// defined in the module to those types' definitions
+type typeExpr struct {
+	// text is the expression formatted as gofmt would on one line e.g. "map[string]*azcore.ETag"
+	text   string
+	tokens []Token
+}
+
+// newTypeExpr returns the typeExpr having the given tokens
+func newTypeExpr(tokens []Token) typeExpr {
+	sb := strings.Builder{}
+	for _, t := range tokens {
+		sb.WriteString(t.Value)
+	}
+	return typeExpr{text: sb.String(), tokens: tokens}
+}
+
+// unlinkedType returns the typeExpr for an expression, whose identifiers don't link to any definition
+func unlinkedType(pkg Pkg, expr ast.Expr) typeExpr {
+	if t, ok := exprType(expr, func(ast.Expr) string { return "" }); ok {
+		return t
+	}
+	return textType(pkg.getText(expr.Pos(), expr.End()))
+}
+
+// textType returns a typeExpr having one token, for a predeclared type or text that isn't a type expression
+func textType(text string) typeExpr {
+	tokens := []Token{}
+	makeToken(nil, nil, text, TokenTypeTypeName, &tokens)
+	return typeExpr{text: text, tokens: tokens}
+}
+
+// exprType makes the typeExpr for a type expression. nav is as for makeExprTokens. It returns false when
+// the expression isn't a type.
+func exprType(expr ast.Expr, nav func(ast.Expr) string) (typeExpr, bool) {
+	tokens := []Token{}
+	if !makeExprTokens(expr, nav, &tokens) {
+		return typeExpr{}, false
+	}
+	return newTypeExpr(tokens), true
+}
+
+// makeTokens appends the expression's tokens to list
+func (t typeExpr) makeTokens(list *[]Token) {
+	*list = append(*list, t.tokens...)
+}
+
+// navigator returns the navigation target of a named type or pointer to one e.g. "pkg.Foo" for "*Foo"
+// or "Foo[int]", or "" when the type isn't defined in the module
+func (t typeExpr) navigator() string {
+	tokens := t.tokens
+	if len(tokens) > 0 && tokens[0].Value == "*" {
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 || tokens[0].NavigateToID == nil {
+		return ""
+	}
+	return *tokens[0].NavigateToID
+}
+
+// unlink returns a copy of the expression in which identifiers linking to the given targets aren't links
+func (t typeExpr) unlink(targets map[string]bool) typeExpr {
+	tokens := make([]Token, len(t.tokens))
+	for i, tok := range t.tokens {
+		if tok.NavigateToID != nil && targets[*tok.NavigateToID] {
+			tok.NavigateToID = nil
+		}
+		tokens[i] = tok
+	}
+	return typeExpr{text: t.text, tokens: tokens}
+}
 
-func makeTypeSectionToken(section string, list *[]Token) {
-	switch {
-	case slices.Contains(keywords, section):
-		makeToken(nil, nil, section, TokenTypeKeyword, list)
-	case slices.Contains(internalTypes, section):
-		makeToken(nil, nil, section, TokenTypeTypeName, list)
-	default:
-		if strings.HasPrefix(section, "<") {
-			splits := strings.Split(section[1:], ">")
-			makeToken(nil, &splits[0], splits[1], TokenTypeTypeName, list)
+func (t typeExpr) String() string {
+	return t.text
+}
+
+// typeTexts returns the text of each type e.g. "*Options"
+func typeTexts(types []typeExpr) []string {
+	texts := make([]string, len(types))
+	for i, t := range types {
+		texts[i] = t.text
+	}
+	return texts
+}
+
+// sortTypes sorts types by their text
+func sortTypes(types []typeExpr) {
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].text < types[j].text
+	})
+}
+
+// makeExprTokens makes tokens for a type expression, formatted as gofmt would on one line. nav returns the
+// navigation target of an identifier or qualified identifier, or "" when it has none. makeExprTokens returns
+// false when the expression isn't a type, in which case the tokens it made are incomplete.
+func makeExprTokens(expr ast.Expr, nav func(ast.Expr) string, list *[]Token) bool {
+	ok := true
+	// arrayLen is greater than zero while walking an array length, which can be any constant expression
+	arrayLen := 0
+	punct := func(s string) {
+		makeToken(nil, nil, s, TokenTypePunctuation, list)
+	}
+	space := func() {
+		makeToken(nil, nil, " ", TokenTypeWhitespace, list)
+	}
+	keyword := func(s string) {
+		makeToken(nil, nil, s, TokenTypeKeyword, list)
+	}
+	typeName := func(e ast.Expr, name string) {
+		switch {
+		case slices.Contains(keywords, name):
+			keyword(name)
+		case slices.Contains(internalTypes, name):
+			makeToken(nil, nil, name, TokenTypeTypeName, list)
+		default:
+			var navID *string
+			if n := nav(e); n != "" {
+				navID = &n
+			}
+			makeToken(nil, navID, name, TokenTypeTypeName, list)
+		}
+	}
+	var walk func(ast.Expr)
+	fields := func(fl *ast.FieldList, sep string) {
+		if fl == nil {
+			return
+		}
+		for i, f := range fl.List {
+			if i > 0 {
+				punct(sep)
+				space()
+			}
+			for j, n := range f.Names {
+				if j > 0 {
+					punct(",")
+					space()
+				}
+				makeToken(nil, nil, n.Name, TokenTypeMemberName, list)
+			}
+			if len(f.Names) > 0 {
+				space()
+			}
+			walk(f.Type)
+			if f.Tag != nil {
+				space()
+				makeToken(nil, nil, f.Tag.Value, TokenTypeStringLiteral, list)
+			}
+		}
+	}
+	signature := func(ft *ast.FuncType) {
+		punct("(")
+		fields(ft.Params, ",")
+		punct(")")
+		if ft.Results == nil || len(ft.Results.List) == 0 {
+			return
+		}
+		space()
+		if r := ft.Results.List; len(r) == 1 && len(r[0].Names) == 0 {
+			walk(r[0].Type)
 		} else {
-			makeToken(nil, nil, section, TokenTypeTypeName, list)
+			punct("(")
+			fields(ft.Results, ",")
+			punct(")")
 		}
 	}
+	// block makes the tokens of a struct or interface type's members, which walkMember makes
+	block := func(fl *ast.FieldList, walkMember func(*ast.Field)) {
+		punct("{")
+		if fl != nil && len(fl.List) > 0 {
+			space()
+			for i, f := range fl.List {
+				if i > 0 {
+					punct(";")
+					space()
+				}
+				walkMember(f)
+			}
+			space()
+		}
+		punct("}")
+	}
+	walk = func(e ast.Expr) {
+		switch x := e.(type) {
+		case *ast.Ident:
+			typeName(x, x.Name)
+		case *ast.SelectorExpr:
+			// qualified identifier e.g. "azcore.ETag"
+			id, isIdent := x.X.(*ast.Ident)
+			if !isIdent {
+				ok = false
+				return
+			}
+			typeName(x, id.Name+"."+x.Sel.Name)
+		case *ast.StarExpr:
+			punct("*")
+			walk(x.X)
+		case *ast.ParenExpr:
+			punct("(")
+			walk(x.X)
+			punct(")")
+		case *ast.Ellipsis:
+			punct("...")
+			if x.Elt != nil {
+				walk(x.Elt)
+			}
+		case *ast.ArrayType:
+			punct("[")
+			if x.Len != nil {
+				arrayLen++
+				walk(x.Len)
+				arrayLen--
+			}
+			punct("]")
+			walk(x.Elt)
+		case *ast.MapType:
+			keyword("map")
+			punct("[")
+			walk(x.Key)
+			punct("]")
+			walk(x.Value)
+		case *ast.ChanType:
+			switch x.Dir {
+			case ast.RECV:
+				punct("<-")
+				keyword("chan")
+			case ast.SEND:
+				keyword("chan")
+				punct("<-")
+			default:
+				keyword("chan")
+			}
+			space()
+			walk(x.Value)
+		case *ast.FuncType:
+			keyword("func")
+			signature(x)
+		case *ast.InterfaceType:
+			keyword("interface")
+			block(x.Methods, func(m *ast.Field) {
+				if ft, isFunc := m.Type.(*ast.FuncType); isFunc && len(m.Names) > 0 {
+					makeToken(nil, nil, m.Names[0].Name, TokenTypeMemberName, list)
+					signature(ft)
+				} else {
+					// embedded interface or type set
+					walk(m.Type)
+				}
+			})
+		case *ast.StructType:
+			keyword("struct")
+			block(x.Fields, func(f *ast.Field) {
+				fields(&ast.FieldList{List: []*ast.Field{f}}, ";")
+			})
+		case *ast.IndexExpr:
+			// generic instantiation e.g. "List[int]"
+			walk(x.X)
+			punct("[")
+			walk(x.Index)
+			punct("]")
+		case *ast.IndexListExpr:
+			walk(x.X)
+			punct("[")
+			for i, index := range x.Indices {
+				if i > 0 {
+					punct(",")
+					space()
+				}
+				walk(index)
+			}
+			punct("]")
+		case *ast.UnaryExpr:
+			// "~int" or, in an array length, e.g. "-1"
+			if x.Op != token.TILDE && arrayLen == 0 {
+				ok = false
+				return
+			}
+			punct(x.Op.String())
+			walk(x.X)
+		case *ast.BinaryExpr:
+			// "~int | ~string" or, in an array length, e.g. "2 * N"
+			if x.Op != token.OR && arrayLen == 0 {
+				ok = false
+				return
+			}
+			walk(x.X)
+			space()
+			punct(x.Op.String())
+			space()
+			walk(x.Y)
+		case *ast.BasicLit:
+			if x.Kind == token.STRING {
+				makeToken(nil, nil, x.Value, TokenTypeStringLiteral, list)
+			} else {
+				makeToken(nil, nil, x.Value, TokenTypeLiteral, list)
+			}
+		default:
+			ok = false
+		}
+	}
+	walk(expr)
+	return ok
 }
+
+var keywords = []string{"interface", "map", "any", "func", "chan", "struct"}
+var internalTypes = []string{"bool", "uint8", "uint16", "uint32", "uint64", "uint", "int8", "int16", "int32", "int64", "int", "float32", "float64", "complex64", "complex128", "byte", "rune", "string", "error", "uintptr", "nil", "comparable"}
@@ -0,0 +1,167 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// varCall is a call initializing a var whose type is the type of one of the called func's results
+type varCall struct {
+	call    *ast.CallExpr
+	imports map[string]string
+	// result is the index of the var's type in the func's results e.g. 1 for b in "var a, b = f()"
+	result int
+}
+
+// resolveVarTypes sets the types of vars initialized by calls to funcs defined in the module, which
+// can't be known until the module's packages have been indexed e.g. "var DefaultClient = NewClient()".
+func (m *Module) resolveVarTypes() {
+	for _, p := range m.packages {
+		for name, vc := range p.c.varCalls {
+			decl, ok := p.c.Vars[name]
+			if !ok {
+				continue
+			}
+			if t := m.callResultType(p, vc); t.text != "" {
+				decl.Type = t
+				p.c.Vars[name] = decl
+			}
+		}
+	}
+}
+
+// callResultType returns the type of a call's result as it would appear in the calling package, or the zero
+// typeExpr when the called func isn't defined in the module or the type can't be determined
+func (m *Module) callResultType(p *Pkg, vc varCall) typeExpr {
+	fun := vc.call.Fun
+	// typeArgs are the explicit type arguments of a call to a generic func e.g. "NewSet[string]()"
+	var typeArgs []ast.Expr
+	switch x := fun.(type) {
+	case *ast.IndexExpr:
+		fun, typeArgs = x.X, []ast.Expr{x.Index}
+	case *ast.IndexListExpr:
+		fun, typeArgs = x.X, x.Indices
+	}
+	source, alias := p, ""
+	var name string
+	switch x := fun.(type) {
+	case *ast.Ident:
+		// var Default = NewClient()
+		name = x.Name
+	case *ast.SelectorExpr:
+		// var Default = client.New()
+		id, ok := x.X.(*ast.Ident)
+		if !ok {
+			return typeExpr{}
+		}
+		impPath, ok := vc.imports[id.Name]
+		if !ok {
+			// a method call, whose receiver's type isn't known
+			return typeExpr{}
+		}
+		if source = m.packageByImportPath(impPath); source == nil {
+			return typeExpr{}
+		}
+		name, alias = x.Sel.Name, id.Name
+	default:
+		return typeExpr{}
+	}
+	fn, ok := source.c.Funcs[name]
+	if !ok || vc.result >= len(fn.Returns) {
+		return typeExpr{}
+	}
+	t := fn.Returns[vc.result]
+	if len(fn.typeParamNames) > 0 {
+		args := make([]typeExpr, len(fn.typeParamNames))
+		for i, arg := range typeArgs {
+			if i < len(args) {
+				args[i] = p.translateExpr(arg, vc.imports)
+			}
+		}
+		inferTypeArgs(fn, vc.call.Args, args)
+		for i, arg := range args {
+			if arg.text == "" && slices.ContainsFunc(t.tokens, func(tok Token) bool { return isTypeParam(tok, fn.typeParamNames[i]) }) {
+				// the type depends on a type argument that can't be inferred
+				return typeExpr{}
+			}
+			t = substituteTypeParam(t, fn.typeParamNames[i], arg)
+		}
+	}
+	if alias != "" {
+		t = qualifyTypes(t, source.Name(), alias)
+	}
+	return t
+}
+
+// packageByImportPath returns the module's package having the given import path, or nil when there's no such package
+func (m *Module) packageByImportPath(impPath string) *Pkg {
+	for _, p := range m.packages {
+		if p.importPath() == impPath {
+			return p
+		}
+	}
+	return nil
+}
+
+// inferTypeArgs infers missing type arguments of a generic func from arguments that are untyped
+// literals passed for parameters whose type is a type parameter e.g. "T" is "int" for "Ptr(42)"
+func inferTypeArgs(fn Func, callArgs []ast.Expr, args []typeExpr) {
+	for i, a := range callArgs {
+		lit, ok := a.(*ast.BasicLit)
+		if !ok || i >= len(fn.paramTypes) {
+			continue
+		}
+		for j, tp := range fn.typeParamNames {
+			if args[j].text == "" && fn.paramTypes[i].text == tp {
+				args[j] = textType(defaultLiteralTypes[lit.Kind])
+			}
+		}
+	}
+}
+
+// defaultLiteralTypes maps kinds of literals to the default types of untyped constants of those kinds
+var defaultLiteralTypes = map[token.Token]string{
+	token.CHAR:   "rune",
+	token.FLOAT:  "float64",
+	token.IMAG:   "complex128",
+	token.INT:    "int",
+	token.STRING: "string",
+}
+
+// substituteTypeParam replaces a type parameter in a type with a type argument e.g. "*Set[T]" becomes "*Set[string]"
+func substituteTypeParam(typ typeExpr, param string, arg typeExpr) typeExpr {
+	tokens := []Token{}
+	for _, t := range typ.tokens {
+		if isTypeParam(t, param) {
+			tokens = append(tokens, arg.tokens...)
+		} else {
+			tokens = append(tokens, t)
+		}
+	}
+	return newTypeExpr(tokens)
+}
+
+// isTypeParam returns true when a type's token is the named type parameter. Identifiers linking to a
+// definition aren't type parameters.
+func isTypeParam(t Token, param string) bool {
+	return t.Kind == TokenTypeTypeName && t.NavigateToID == nil && t.Value == param
+}
+
+// qualifyTypes qualifies the names of types defined in the named package with the alias another package imports
+// it by e.g. "*Client" linking to "sdk/clients.Client" becomes "*clients.Client"
+func qualifyTypes(typ typeExpr, pkgName, alias string) typeExpr {
+	tokens := make([]Token, len(typ.tokens))
+	for i, t := range typ.tokens {
+		if t.NavigateToID != nil && strings.HasPrefix(*t.NavigateToID, pkgName+".") && !strings.Contains(t.Value, ".") {
+			t.Value = alias + "." + t.Value
+		}
+		tokens[i] = t
+	}
+	return newTypeExpr(tokens)
+}